@@ -0,0 +1,67 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/muhlemmer/pg_testdata/loader"
+	"github.com/muhlemmer/pg_testdata/parse"
+)
+
+// dryRun loads conf into a loader.DryRun writing to w, one table at a time.
+func dryRun(ctx context.Context, w io.Writer, conf *parse.Config, opts options) {
+	d, err := loader.NewDryRun(w, opts.format)
+	if err != nil {
+		panic(err)
+	}
+
+	l := loader.New(conf, d)
+	l.Reset = opts.reset
+
+	if _, err = l.Load(ctx); err != nil {
+		panic(err)
+	}
+}
+
+// writeDryRun writes the statements loading conf to the output of opts,
+// without connecting to the database. Foreign keys are not read from the database,
+// so tables are only ordered by their reference columns.
+func writeDryRun(ctx context.Context, conf *parse.Config, opts options) {
+	if opts.output == "" {
+		dryRun(ctx, os.Stdout, conf, opts)
+		return
+	}
+
+	f, err := os.Create(opts.output)
+	if err != nil {
+		panic(fmt.Errorf("main.writeDryRun: %w", err))
+	}
+	defer f.Close()
+
+	dryRun(ctx, f, conf, opts)
+
+	if err = f.Close(); err != nil {
+		panic(fmt.Errorf("main.writeDryRun: %w", err))
+	}
+}
@@ -0,0 +1,186 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/muhlemmer/pg_testdata/loader"
+	"github.com/muhlemmer/pg_testdata/parse"
+)
+
+func Test_writeDryRun(t *testing.T) {
+	config := func(table *parse.Table) *parse.Config {
+		table.Columns = append(table.Columns, &parse.Column{
+			Name: "bool_col",
+			Type: parse.BoolType,
+			Generator: map[parse.ArgName]interface{}{
+				parse.ProbabilityArg: 100,
+			},
+		})
+		return &parse.Config{Tables: []*parse.Table{table}}
+	}
+
+	tests := []struct {
+		name    string
+		conf    *parse.Config
+		opts    options
+		want    string
+		wantErr bool
+	}{
+		{
+			"Unsupported format",
+			config(&parse.Table{Name: "unit_tests", Amount: 2}),
+			options{format: "foo"},
+			"",
+			true,
+		},
+		{
+			"Reference",
+			&parse.Config{
+				Transaction: parse.AllTransaction,
+				Tables: []*parse.Table{
+					{
+						Name:   "children",
+						Amount: 2,
+						Columns: []*parse.Column{
+							{
+								Name: "parent_id",
+								Type: parse.ReferenceType,
+								Generator: map[parse.ArgName]interface{}{
+									parse.TableArg:  "parents",
+									parse.ColumnArg: "id",
+								},
+							},
+						},
+					},
+					{
+						Name:   "parents",
+						Amount: 1,
+						Columns: []*parse.Column{
+							{
+								Name:      "id",
+								Type:      parse.Int4Type,
+								Generator: map[parse.ArgName]interface{}{parse.ModeArg: parse.SequenceMode},
+							},
+						},
+					},
+				},
+			},
+			options{},
+			"begin;\ninsert into parents (id) values ('1');\ninsert into children (parent_id) values ('1');\ninsert into children (parent_id) values ('1');\ncommit;\n",
+			false,
+		},
+		{
+			"SQL",
+			config(&parse.Table{Name: "unit_tests", Amount: 3, BatchSize: 2}),
+			options{format: loader.SQLFormat},
+			"insert into unit_tests (bool_col) values ('t'), ('t');\ninsert into unit_tests (bool_col) values ('t');\n",
+			false,
+		},
+		{
+			"Transactions",
+			config(&parse.Table{Name: "unit_tests", Amount: 3, Before: parse.BeforeDelete, Transaction: parse.BatchTransaction, CommitEvery: 2}),
+			options{},
+			"begin;\ndelete from \"unit_tests\";\ncommit;\nbegin;\ninsert into unit_tests (bool_col) values ('t');\ninsert into unit_tests (bool_col) values ('t');\ncommit;\nbegin;\ninsert into unit_tests (bool_col) values ('t');\ncommit;\n",
+			false,
+		},
+		{
+			"Copy",
+			config(&parse.Table{Name: "unit_tests", Amount: 2}),
+			options{format: loader.CopyFormat, reset: true},
+			"begin;\ntruncate table \"unit_tests\";\ncommit;\ncopy unit_tests (bool_col) from stdin;\nt\nt\n\\.\n",
+			false,
+		},
+		{
+			"Copy on conflict",
+			config(&parse.Table{Name: "unit_tests", Amount: 2, OnConflict: &parse.OnConflict{Action: parse.ConflictNothing}}),
+			options{format: loader.CopyFormat},
+			"",
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.output = filepath.Join(t.TempDir(), "out.sql")
+
+			err := func() (err error) {
+				defer func() { err, _ = recover().(error) }()
+				writeDryRun(testCtx, tt.conf, tt.opts)
+
+				return
+			}()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("writeDryRun() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+
+			got, err := os.ReadFile(tt.opts.output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("writeDryRun() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// Test_regression_dry_run checks that the statements of a dry run
+// load the same data as inserts.
+func Test_regression_dry_run(t *testing.T) {
+	conf, err := parse.Load("testdata/regression_test.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf.Tables[0].Name = "regression_dry_run_tests"
+	conf.Tables[0].BatchSize = 100
+
+	output := filepath.Join(t.TempDir(), "out.sql")
+	writeDryRun(testCtx, conf, options{output: output})
+
+	script, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runWithCtxTimeout(testCtx, 10*time.Second, func(ctx context.Context) {
+		if _, err := testDB.Exec(ctx, string(script)); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	got := queryRegressionData(t, "regression_dry_run_tests", "")
+	want := readRegressionData(t)
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("regression got =\n%v\nwant\n%v", got, want)
+	}
+}
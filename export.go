@@ -0,0 +1,57 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/muhlemmer/pg_testdata/loader"
+	"github.com/muhlemmer/pg_testdata/parse"
+)
+
+// exportFiles writes a file for each table of conf to the output directory of opts,
+// in a file format, without connecting to the database.
+// The output directory defaults to the working directory.
+func exportFiles(ctx context.Context, conf *parse.Config, opts options) {
+	dir := opts.output
+	if dir == "" {
+		dir = "."
+	}
+
+	e, err := loader.NewExporter(dir, opts.format)
+	if err != nil {
+		panic(err)
+	}
+
+	l := loader.New(conf, e)
+	l.Parallel = opts.parallel
+	l.Reset = opts.reset
+	l.Report = func(r loader.Result) { log.Print(r) }
+
+	_, err = l.Load(ctx)
+	if cerr := e.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,167 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/muhlemmer/pg_testdata/loader"
+	"github.com/muhlemmer/pg_testdata/parse"
+)
+
+func Test_exportFiles(t *testing.T) {
+	column := &parse.Column{
+		Name: "bool_col",
+		Type: parse.BoolType,
+		Generator: map[parse.ArgName]interface{}{
+			parse.ProbabilityArg: 100,
+		},
+	}
+	reference := &parse.Column{
+		Name: "parent_id",
+		Type: parse.ReferenceType,
+		Generator: map[parse.ArgName]interface{}{
+			parse.TableArg:  "unit_tests",
+			parse.ColumnArg: "bool_col",
+		},
+	}
+
+	tests := []struct {
+		name    string
+		conf    *parse.Config
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			"Missing reference",
+			&parse.Config{
+				Tables: []*parse.Table{
+					{Name: "reference_tests", Amount: 2, Columns: []*parse.Column{reference}},
+				},
+			},
+			map[string]string{},
+			true,
+		},
+		{
+			"Reference",
+			&parse.Config{
+				Tables: []*parse.Table{
+					{Name: "reference_tests", Amount: 2, Columns: []*parse.Column{reference}},
+					{Name: "unit_tests", Amount: 2, Columns: []*parse.Column{column}},
+				},
+			},
+			map[string]string{
+				"unit_tests.csv":      "bool_col\nt\nt\n",
+				"reference_tests.csv": "parent_id\nt\nt\n",
+			},
+			false,
+		},
+		{
+			"Chunks",
+			&parse.Config{
+				Tables: []*parse.Table{
+					{Name: "unit_tests", Amount: 3, ChunkSize: 2, Columns: []*parse.Column{column}},
+					{Name: "more_tests", Amount: 1, Columns: []*parse.Column{column}},
+				},
+			},
+			map[string]string{
+				"unit_tests.csv": "bool_col\nt\nt\nt\n",
+				"more_tests.csv": "bool_col\nt\n",
+			},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			err := func() (err error) {
+				defer func() { err, _ = recover().(error) }()
+				exportFiles(testCtx, tt.conf, options{format: loader.CSVFormat, output: dir})
+
+				return
+			}()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("exportFiles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make(map[string]string, len(entries))
+			for _, e := range entries {
+				b, err := os.ReadFile(filepath.Join(dir, e.Name()))
+				if err != nil {
+					t.Fatal(err)
+				}
+				got[e.Name()] = string(b)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("exportFiles() =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Test_regression_export checks that an exported CSV file
+// loads the same data as inserts.
+func Test_regression_export(t *testing.T) {
+	conf, err := parse.Load("testdata/regression_test.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf.Tables[0].Name = "regression_export_tests"
+
+	dir := t.TempDir()
+	exportFiles(testCtx, conf, options{format: loader.CSVFormat, output: dir})
+
+	f, err := os.Open(filepath.Join(dir, "regression_export_tests.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	runWithCtxTimeout(testCtx, 10*time.Second, func(ctx context.Context) {
+		conn, err := testDB.Acquire(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Release()
+
+		if _, err := conn.Conn().PgConn().CopyFrom(ctx, f, "copy regression_export_tests from stdin (format csv, header)"); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	got := queryRegressionData(t, "regression_export_tests", "")
+	want := readRegressionData(t)
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("regression got =\n%v\nwant\n%v", got, want)
+	}
+}
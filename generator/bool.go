@@ -21,6 +21,8 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 package generator
 
 import (
+	"math/big"
+
 	"github.com/jackc/pgtype"
 )
 
@@ -29,6 +31,13 @@ type boolType struct {
 	generator *probability
 }
 
+func (b *boolType) cardinality() *big.Int {
+	if p := b.generator.probability; p <= 0 || p >= maxProbability {
+		return big.NewInt(1)
+	}
+	return big.NewInt(2)
+}
+
 func (b *boolType) NextValue() {
 	b.Bool.Bool = b.generator.get()
 	b.Bool.Status = pgtype.Present
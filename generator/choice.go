@@ -0,0 +1,135 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"math/big"
+	"math/rand"
+	"sort"
+	"unicode/utf8"
+)
+
+// cumulative returns the running totals of weights,
+// or of n equal weights if weights is nil.
+func cumulative(n int, weights []float64) []float64 {
+	cum := make([]float64, n)
+
+	var total float64
+	for i := range cum {
+		if weights == nil {
+			total++
+		} else {
+			total += weights[i]
+		}
+		cum[i] = total
+	}
+
+	return cum
+}
+
+// pick a random index from cumulative weights, using r as source.
+func pick(r *rand.Rand, cum []float64) int {
+	x := r.Float64() * cum[len(cum)-1]
+	return sort.Search(len(cum), func(i int) bool { return cum[i] > x })
+}
+
+// positive reports if the value at index i of cumulative weights
+// has a positive weight and thus can be picked.
+func positive(cum []float64, i int) bool {
+	if i == 0 {
+		return cum[0] > 0
+	}
+	return cum[i] > cum[i-1]
+}
+
+type choiceString struct {
+	values     []string
+	cumulative []float64
+}
+
+// ChoiceString returns a StringSource which picks from values.
+// Weights are the relative chance of the value with the same index
+// to be picked. If weights is nil, all values have the same chance.
+// Otherwise it must have the same length as values,
+// and consist of non-negative weights with a positive sum.
+func ChoiceString(values []string, weights []float64) StringSource {
+	return choiceString{values, cumulative(len(values), weights)}
+}
+
+func (s choiceString) string(r *rand.Rand) string {
+	return s.values[pick(r, s.cumulative)]
+}
+
+func (s choiceString) maxChars() int {
+	var n int
+	for _, v := range s.values {
+		if l := utf8.RuneCountInString(v); l > n {
+			n = l
+		}
+	}
+	return n
+}
+
+func (s choiceString) cardinality() *big.Int {
+	distinct := make(map[string]struct{}, len(s.values))
+	for i, v := range s.values {
+		if positive(s.cumulative, i) {
+			distinct[v] = struct{}{}
+		}
+	}
+	return big.NewInt(int64(len(distinct)))
+}
+
+// intChoice is a pseudo-random and deterministic generator,
+// picking from values with cumulative weights.
+type intChoice struct {
+	rand       *rand.Rand
+	values     []int64
+	cumulative []float64
+}
+
+func newIntChoice(seed int64, values []int64, weights []float64) *intChoice {
+	return &intChoice{
+		rand: rand.New(
+			rand.NewSource(seed),
+		),
+		values:     values,
+		cumulative: cumulative(len(values), weights),
+	}
+}
+
+// get the next randomly picked value.
+func (g *intChoice) get() int64 {
+	return g.values[pick(g.rand, g.cumulative)]
+}
+
+// skip is a no-op, as random values do not depend on each other.
+func (g *intChoice) skip() {}
+
+func (g *intChoice) cardinality() *big.Int {
+	distinct := make(map[int64]struct{}, len(g.values))
+	for i, v := range g.values {
+		if positive(g.cumulative, i) {
+			distinct[v] = struct{}{}
+		}
+	}
+	return big.NewInt(int64(len(distinct)))
+}
@@ -0,0 +1,132 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func Test_cumulative(t *testing.T) {
+	tests := []struct {
+		name    string
+		n       int
+		weights []float64
+		want    []float64
+	}{
+		{
+			"uniform",
+			3,
+			nil,
+			[]float64{1, 2, 3},
+		},
+		{
+			"weighted",
+			3,
+			[]float64{80, 15, 5},
+			[]float64{80, 95, 100},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cumulative(tt.n, tt.weights); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cumulative() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_choiceString(t *testing.T) {
+	tests := []struct {
+		name   string
+		source StringSource
+		want   []string
+	}{
+		{
+			"uniform",
+			ChoiceString([]string{"a", "b", "c"}, nil),
+			[]string{"b", "c", "b", "b", "b", "c", "a", "a", "a", "a"},
+		},
+		{
+			"weighted",
+			ChoiceString([]string{"active", "suspended", "deleted"}, []float64{80, 15, 5}),
+			[]string{"active", "suspended", "active", "active", "active", "active", "active", "active", "active", "active"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := rand.New(rand.NewSource(1))
+			got := make([]string, len(tt.want))
+
+			for i := range got {
+				got[i] = tt.source.string(r)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("choiceString.string() =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_choiceString_maxChars(t *testing.T) {
+	if got := MaxLength(ChoiceString([]string{"a", "ééé", "bb"}, nil)); got != 3 {
+		t.Errorf("MaxLength() = %d, want 3", got)
+	}
+}
+
+func Test_intChoice(t *testing.T) {
+	tests := []struct {
+		name string
+		v    Value
+		want []interface{}
+	}{
+		{
+			"int2",
+			NewInt2Choice(1, 0, []int16{1, 2, 3}, nil),
+			[]interface{}{int16(2), int16(3), int16(2), int16(2), int16(2)},
+		},
+		{
+			"int4 zero weight",
+			NewInt4Choice(1, 0, []int32{1, 2, 3}, []float64{0, 1, 1}),
+			[]interface{}{int32(3), int32(3), int32(3), int32(2), int32(2)},
+		},
+		{
+			"int8 null",
+			NewInt8Choice(1, 100, []int64{1, 2, 3}, nil),
+			[]interface{}{nil, nil, nil, nil, nil},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]interface{}, len(tt.want))
+
+			for i := range got {
+				got[i] = tt.v.Get()
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("valueGenerator.Get() =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Distribution of pseudo-random floating point values.
+type Distribution interface {
+	// float64 returns the next value, using r as source.
+	float64(r *rand.Rand) float64
+}
+
+type uniform struct {
+	min, max float64
+}
+
+// Uniform distribution between min, inclusive, and max, exclusive.
+func Uniform(min, max float64) Distribution {
+	return uniform{min, max}
+}
+
+func (d uniform) float64(r *rand.Rand) float64 {
+	// Interpolate, so that max - min does not overflow.
+	f := r.Float64()
+	return d.min*(1-f) + d.max*f
+}
+
+type normal struct {
+	mean, stdDev float64
+	min, max     float64
+}
+
+// Normal (Gaussian) distribution with mean and standard deviation stdDev.
+func Normal(mean, stdDev float64) Distribution {
+	return ClampedNormal(mean, stdDev, math.Inf(-1), math.Inf(1))
+}
+
+// ClampedNormal is a Normal distribution where values below min
+// or above max are replaced by min or max respectively.
+func ClampedNormal(mean, stdDev, min, max float64) Distribution {
+	return normal{mean, stdDev, min, max}
+}
+
+func (d normal) float64(r *rand.Rand) float64 {
+	return math.Max(d.min, math.Min(d.max, r.NormFloat64()*d.stdDev+d.mean))
+}
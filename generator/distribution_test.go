@@ -0,0 +1,70 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestDistribution(t *testing.T) {
+	tests := []struct {
+		name string
+		d    Distribution
+		want []float64
+	}{
+		{
+			"Uniform",
+			Uniform(-1, 1),
+			[]float64{0.20932057595923914, 0.8810181760900249, 0.32912010643698086, -0.12457162562603963, -0.15072500585746867},
+		},
+		{
+			"Uniform single value",
+			Uniform(3, 3),
+			[]float64{3, 3, 3},
+		},
+		{
+			"Normal",
+			Normal(10, 2),
+			[]float64{7.532483644804106, 9.747304978595254, 8.958010857693699, 14.57143823539916, 10.64561050522316},
+		},
+		{
+			"ClampedNormal",
+			ClampedNormal(10, 2, 9, 11),
+			[]float64{9, 9.747304978595254, 9, 11, 10.64561050522316},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := rand.New(rand.NewSource(1))
+			got := make([]float64, len(tt.want))
+
+			for i := range got {
+				got[i] = tt.d.float64(r)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Distribution.float64() =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
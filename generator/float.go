@@ -0,0 +1,77 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"math/rand"
+
+	"github.com/jackc/pgtype"
+)
+
+type float4Type struct {
+	pgtype.Float4
+	rand         *rand.Rand
+	distribution Distribution
+}
+
+func (f *float4Type) NextValue() {
+	f.Float4.Float = float32(f.distribution.float64(f.rand))
+	f.Float4.Status = pgtype.Present
+}
+
+// NewFloat4 returns a single precision floating point value generator.
+// Values are drawn from distribution.
+func NewFloat4(seed int64, nullProbabilty float32, distribution Distribution) Value {
+	return &value{
+		Value: &float4Type{
+			rand: rand.New(
+				rand.NewSource(seed),
+			),
+			distribution: distribution,
+		},
+		nulls: newNull(seed, nullProbabilty),
+	}
+}
+
+type float8Type struct {
+	pgtype.Float8
+	rand         *rand.Rand
+	distribution Distribution
+}
+
+func (f *float8Type) NextValue() {
+	f.Float8.Float = f.distribution.float64(f.rand)
+	f.Float8.Status = pgtype.Present
+}
+
+// NewFloat8 returns a double precision floating point value generator.
+// Values are drawn from distribution.
+func NewFloat8(seed int64, nullProbabilty float32, distribution Distribution) Value {
+	return &value{
+		Value: &float8Type{
+			rand: rand.New(
+				rand.NewSource(seed),
+			),
+			distribution: distribution,
+		},
+		nulls: newNull(seed, nullProbabilty),
+	}
+}
@@ -0,0 +1,92 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_float4Type(t *testing.T) {
+	type args struct {
+		seed            int64
+		nullProbability float32
+		distribution    Distribution
+	}
+	tests := []struct {
+		name string
+		args args
+		want interface{}
+	}{
+		{
+			"null",
+			args{1, 100, Uniform(0, 100)},
+			nil,
+		},
+		{
+			"random",
+			args{3, 0, Uniform(0, 100)},
+			float32(71.99827),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewFloat4(tt.args.seed, tt.args.nullProbability, tt.args.distribution)
+
+			if got := v.Get(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("valueGenerator.Get() = %T(%v), want %T(%v)", got, got, tt.want, tt.want)
+			}
+		})
+	}
+}
+
+func Test_float8Type(t *testing.T) {
+	type args struct {
+		seed            int64
+		nullProbability float32
+		distribution    Distribution
+	}
+	tests := []struct {
+		name string
+		args args
+		want interface{}
+	}{
+		{
+			"null",
+			args{1, 100, Normal(0, 1)},
+			nil,
+		},
+		{
+			"random",
+			args{3, 0, Normal(0, 1)},
+			-0.8585823364203016,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewFloat8(tt.args.seed, tt.args.nullProbability, tt.args.distribution)
+
+			if got := v.Get(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("valueGenerator.Get() = %T(%v), want %T(%v)", got, got, tt.want, tt.want)
+			}
+		})
+	}
+}
@@ -57,6 +57,9 @@ type value struct {
 
 func (v *value) nextStatusValue() {
 	if v.nulls != nil && v.nulls.get() {
+		if s, ok := v.Value.(skipper); ok {
+			s.skip()
+		}
 		v.Set(nil)
 		return
 	}
@@ -82,3 +85,12 @@ func (v value) Get() interface{} {
 	v.nextStatusValue()
 	return v.Value.Get()
 }
+
+// PreferredParamFormat returns the preferred format of the generated values,
+// which is binary unless the type prefers otherwise.
+func (v value) PreferredParamFormat() int16 {
+	if p, ok := v.Value.(pgtype.ParamFormatPreferrer); ok {
+		return p.PreferredParamFormat()
+	}
+	return pgtype.BinaryFormatCode
+}
@@ -0,0 +1,200 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"math/big"
+
+	"github.com/jackc/pgtype"
+)
+
+type int2Type struct {
+	pgtype.Int2
+	generator intGenerator
+}
+
+func (i *int2Type) skip() {
+	i.generator.skip()
+}
+
+func (i *int2Type) cardinality() *big.Int {
+	return cardinalityOf(i.generator)
+}
+
+func (i *int2Type) NextValue() {
+	i.Int2.Int = int16(i.generator.get())
+	i.Int2.Status = pgtype.Present
+}
+
+// NewInt2 returns a 2 byte integer value generator.
+//
+// Values are uniformly distributed between min and max, inclusive.
+// Min must not be greater than max.
+func NewInt2(seed int64, nullProbabilty float32, min, max int16) Value {
+	return &value{
+		Value: &int2Type{
+			generator: newIntRange(seed, int64(min), int64(max)),
+		},
+		nulls: newNull(seed, nullProbabilty),
+	}
+}
+
+type int4Type struct {
+	pgtype.Int4
+	generator intGenerator
+}
+
+func (i *int4Type) skip() {
+	i.generator.skip()
+}
+
+func (i *int4Type) cardinality() *big.Int {
+	return cardinalityOf(i.generator)
+}
+
+func (i *int4Type) NextValue() {
+	i.Int4.Int = int32(i.generator.get())
+	i.Int4.Status = pgtype.Present
+}
+
+// NewInt4 returns a 4 byte integer value generator.
+//
+// Values are uniformly distributed between min and max, inclusive.
+// Min must not be greater than max.
+func NewInt4(seed int64, nullProbabilty float32, min, max int32) Value {
+	return &value{
+		Value: &int4Type{
+			generator: newIntRange(seed, int64(min), int64(max)),
+		},
+		nulls: newNull(seed, nullProbabilty),
+	}
+}
+
+type int8Type struct {
+	pgtype.Int8
+	generator intGenerator
+}
+
+func (i *int8Type) skip() {
+	i.generator.skip()
+}
+
+func (i *int8Type) cardinality() *big.Int {
+	return cardinalityOf(i.generator)
+}
+
+func (i *int8Type) NextValue() {
+	i.Int8.Int = i.generator.get()
+	i.Int8.Status = pgtype.Present
+}
+
+// NewInt8 returns an 8 byte integer value generator.
+//
+// Values are uniformly distributed between min and max, inclusive.
+// Min must not be greater than max.
+func NewInt8(seed int64, nullProbabilty float32, min, max int64) Value {
+	return &value{
+		Value: &int8Type{
+			generator: newIntRange(seed, min, max),
+		},
+		nulls: newNull(seed, nullProbabilty),
+	}
+}
+
+// NewInt2Sequence returns a 2 byte integer value generator,
+// with the same behavior as NewInt4Sequence.
+func NewInt2Sequence(seed int64, nullProbabilty float32, start, step, jitter int16) Value {
+	return &value{
+		Value: &int2Type{
+			generator: newSequence(seed, int64(start), int64(step), int64(jitter)),
+		},
+		nulls: newNull(seed, nullProbabilty),
+	}
+}
+
+// NewInt4Sequence returns a 4 byte integer value generator,
+// which generates the sequence start + n*step, where n is the row number
+// starting at 0. Rows for which a null is generated still count for n.
+// Each value is shifted by a random jitter between -jitter and +jitter, inclusive.
+// Jitter must not be negative. Values must fit into int32.
+func NewInt4Sequence(seed int64, nullProbabilty float32, start, step, jitter int32) Value {
+	return &value{
+		Value: &int4Type{
+			generator: newSequence(seed, int64(start), int64(step), int64(jitter)),
+		},
+		nulls: newNull(seed, nullProbabilty),
+	}
+}
+
+// NewInt8Sequence returns an 8 byte integer value generator,
+// with the same behavior as NewInt4Sequence.
+func NewInt8Sequence(seed int64, nullProbabilty float32, start, step, jitter int64) Value {
+	return &value{
+		Value: &int8Type{
+			generator: newSequence(seed, start, step, jitter),
+		},
+		nulls: newNull(seed, nullProbabilty),
+	}
+}
+
+// NewInt2Choice returns a 2 byte integer value generator,
+// with the same behavior as NewInt4Choice.
+func NewInt2Choice(seed int64, nullProbabilty float32, values []int16, weights []float64) Value {
+	ints := make([]int64, len(values))
+	for i, v := range values {
+		ints[i] = int64(v)
+	}
+
+	return &value{
+		Value: &int2Type{
+			generator: newIntChoice(seed, ints, weights),
+		},
+		nulls: newNull(seed, nullProbabilty),
+	}
+}
+
+// NewInt4Choice returns a 4 byte integer value generator,
+// which picks from values. Weights are the relative chance of the value
+// with the same index to be picked, as with ChoiceString.
+func NewInt4Choice(seed int64, nullProbabilty float32, values []int32, weights []float64) Value {
+	ints := make([]int64, len(values))
+	for i, v := range values {
+		ints[i] = int64(v)
+	}
+
+	return &value{
+		Value: &int4Type{
+			generator: newIntChoice(seed, ints, weights),
+		},
+		nulls: newNull(seed, nullProbabilty),
+	}
+}
+
+// NewInt8Choice returns an 8 byte integer value generator,
+// with the same behavior as NewInt4Choice.
+func NewInt8Choice(seed int64, nullProbabilty float32, values []int64, weights []float64) Value {
+	return &value{
+		Value: &int8Type{
+			generator: newIntChoice(seed, values, weights),
+		},
+		nulls: newNull(seed, nullProbabilty),
+	}
+}
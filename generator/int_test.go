@@ -0,0 +1,140 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_int2Type(t *testing.T) {
+	type args struct {
+		seed            int64
+		nullProbability float32
+		min, max        int16
+	}
+	tests := []struct {
+		name string
+		args args
+		want interface{}
+	}{
+		{
+			"null",
+			args{1, 100, 1, 100},
+			nil,
+		},
+		{
+			"single value",
+			args{1, 0, 42, 42},
+			int16(42),
+		},
+		{
+			"random",
+			args{3, 0, -100, 100},
+			int16(43),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewInt2(tt.args.seed, tt.args.nullProbability, tt.args.min, tt.args.max)
+
+			if got := v.Get(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("valueGenerator.Get() = %T(%v), want %T(%v)", got, got, tt.want, tt.want)
+			}
+		})
+	}
+}
+
+func Test_int4Type(t *testing.T) {
+	type args struct {
+		seed            int64
+		nullProbability float32
+		min, max        int32
+	}
+	tests := []struct {
+		name string
+		args args
+		want interface{}
+	}{
+		{
+			"null",
+			args{1, 100, 1, 100},
+			nil,
+		},
+		{
+			"single value",
+			args{1, 0, 42, 42},
+			int32(42),
+		},
+		{
+			"random",
+			args{3, 0, 1, 100},
+			int32(62),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewInt4(tt.args.seed, tt.args.nullProbability, tt.args.min, tt.args.max)
+
+			if got := v.Get(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("valueGenerator.Get() = %T(%v), want %T(%v)", got, got, tt.want, tt.want)
+			}
+		})
+	}
+}
+
+func Test_int8Type(t *testing.T) {
+	type args struct {
+		seed            int64
+		nullProbability float32
+		min, max        int64
+	}
+	tests := []struct {
+		name string
+		args args
+		want interface{}
+	}{
+		{
+			"null",
+			args{1, 100, 1, 100},
+			nil,
+		},
+		{
+			"single value",
+			args{1, 0, 42, 42},
+			int64(42),
+		},
+		{
+			"random",
+			args{3, 0, -1e12, 1e12},
+			int64(-985229262473),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewInt8(tt.args.seed, tt.args.nullProbability, tt.args.min, tt.args.max)
+
+			if got := v.Get(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("valueGenerator.Get() = %T(%v), want %T(%v)", got, got, tt.want, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+)
+
+// intRange is a pseudo-random and deterministic generator of uniformly
+// distributed integers, within the inclusive bounds of min and max.
+type intRange struct {
+	rand     *rand.Rand
+	min, max int64
+}
+
+// newIntRange returns an intRange, with the random source initialized with seed.
+// Min must not be greater than max.
+func newIntRange(seed, min, max int64) *intRange {
+	return &intRange{
+		rand: rand.New(
+			rand.NewSource(seed),
+		),
+		min: min,
+		max: max,
+	}
+}
+
+// get the next random integer.
+func (g *intRange) get() int64 {
+	// Unsigned arithmetic, so that the full int64 range does not overflow.
+	span := uint64(g.max) - uint64(g.min)
+	if span < math.MaxInt64 {
+		return g.min + g.rand.Int63n(int64(span)+1)
+	}
+
+	for {
+		if v := g.rand.Uint64(); v <= span {
+			return g.min + int64(v)
+		}
+	}
+}
+
+func (g *intRange) cardinality() *big.Int {
+	n := new(big.Int).Sub(big.NewInt(g.max), big.NewInt(g.min))
+	return limitCardinality(n.Add(n, big.NewInt(1)))
+}
+
+// skip is a no-op, as random values do not depend on each other.
+func (g *intRange) skip() {}
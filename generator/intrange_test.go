@@ -0,0 +1,64 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func Test_intRange_get(t *testing.T) {
+	tests := []struct {
+		name string
+		g    *intRange
+		want []int64
+	}{
+		{
+			"single value",
+			newIntRange(1, 7, 7),
+			[]int64{7, 7, 7, 7, 7},
+		},
+		{
+			"small range",
+			newIntRange(1, -5, 5),
+			[]int64{-3, -2, -2, -4, 1, -4, -2, 3, -4, 1},
+		},
+		{
+			"full range",
+			newIntRange(1, math.MinInt64, math.MaxInt64),
+			[]int64{-3646365244906996398, -548706813772622257, 6129484611666145821},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]int64, len(tt.want))
+
+			for i := range got {
+				got[i] = tt.g.get()
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("intRange.get() =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
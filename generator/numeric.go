@@ -0,0 +1,71 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"math/big"
+	"math/rand"
+
+	"github.com/jackc/pgtype"
+)
+
+type numericType struct {
+	pgtype.Numeric
+	rand      *rand.Rand
+	min, span *big.Int
+	exp       int32
+}
+
+func (n *numericType) cardinality() *big.Int {
+	return limitCardinality(n.span)
+}
+
+func (n *numericType) NextValue() {
+	v := new(big.Int).Rand(n.rand, n.span)
+
+	n.Numeric.Int = v.Add(v, n.min)
+	n.Numeric.Exp = n.exp
+	n.Numeric.NaN = false
+	n.Numeric.Status = pgtype.Present
+}
+
+// NewNumeric returns an arbitrary precision numeric value generator.
+//
+// Min and max are unscaled integers: the decimal bound multiplied by 10^scale.
+// For example, a min of 12345 with a scale of 2 represents 123.45.
+// Values are uniformly distributed between min and max, inclusive,
+// and always have exactly scale decimal digits.
+// Min must not be greater than max and scale must not be negative.
+func NewNumeric(seed int64, nullProbabilty float32, min, max *big.Int, scale int32) Value {
+	span := new(big.Int).Sub(max, min)
+
+	return &value{
+		Value: &numericType{
+			rand: rand.New(
+				rand.NewSource(seed),
+			),
+			min:  new(big.Int).Set(min),
+			span: span.Add(span, big.NewInt(1)),
+			exp:  -scale,
+		},
+		nulls: newNull(seed, nullProbabilty),
+	}
+}
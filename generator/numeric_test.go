@@ -0,0 +1,71 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"math/big"
+	"testing"
+)
+
+func Test_numericType(t *testing.T) {
+	type args struct {
+		seed            int64
+		nullProbability float32
+		min, max        int64
+		scale           int32
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			"null",
+			args{1, 100, 1, 100, 0},
+			[]string{"", ""},
+		},
+		{
+			"single value",
+			args{1, 0, 5, 5, 0},
+			[]string{"5e0", "5e0"},
+		},
+		{
+			"scaled",
+			args{3, 0, -10000, 10000, 2},
+			[]string{"8624e-2", "-4301e-2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewNumeric(tt.args.seed, tt.args.nullProbability, big.NewInt(tt.args.min), big.NewInt(tt.args.max), tt.args.scale)
+
+			for i, want := range tt.want {
+				buf, err := v.EncodeText(nil, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := string(buf); got != want {
+					t.Errorf("valueGenerator.EncodeText() %d = %v, want %v", i, got, want)
+				}
+			}
+		})
+	}
+}
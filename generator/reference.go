@@ -0,0 +1,92 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"math/big"
+	"math/rand"
+
+	"github.com/jackc/pgtype"
+)
+
+// Encoded is a value in the PostgreSQL text format,
+// as read from a result row.
+type Encoded struct {
+	Text []byte
+}
+
+type referenceType struct {
+	pgtype.Text
+	rand   *rand.Rand
+	zipf   *rand.Zipf
+	values []Encoded
+}
+
+func (r *referenceType) NextValue() {
+	var i int
+	if r.zipf != nil {
+		i = int(r.zipf.Uint64())
+	} else {
+		i = r.rand.Intn(len(r.values))
+	}
+
+	r.Text.String = string(r.values[i].Text)
+	r.Text.Status = pgtype.Present
+}
+
+func (r *referenceType) cardinality() *big.Int {
+	distinct := make(map[string]struct{}, len(r.values))
+	for _, v := range r.values {
+		distinct[string(v.Text)] = struct{}{}
+	}
+	return big.NewInt(int64(len(distinct)))
+}
+
+// NewReference returns a value generator which picks from values,
+// as read from a referenced column.
+// It prefers the text format for parameters, so the referenced
+// and referencing column do not need to have the same type.
+// Where the binary format is required, such as COPY,
+// the text must be converted to the type of the referencing column.
+//
+// With a skew of 0, values are picked uniformly.
+// A skew greater than 1 picks values by a Zipf distribution, with skew as exponent,
+// where the first values are picked most often.
+// Values must not be empty.
+func NewReference(seed int64, nullProbabilty float32, values []Encoded, skew float64) Value {
+	r := rand.New(
+		rand.NewSource(seed),
+	)
+
+	var zipf *rand.Zipf
+	if skew > 1 {
+		zipf = rand.NewZipf(r, skew, 1, uint64(len(values)-1))
+	}
+
+	return &value{
+		Value: &referenceType{
+			rand:   r,
+			zipf:   zipf,
+			values: values,
+		},
+		nulls: newNull(seed, nullProbabilty),
+	}
+}
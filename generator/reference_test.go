@@ -0,0 +1,85 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgtype"
+)
+
+var testReferenced = []Encoded{
+	{[]byte("1")},
+	{[]byte("2")},
+	{[]byte("3")},
+}
+
+func TestNewReference(t *testing.T) {
+	tests := []struct {
+		name string
+		skew float64
+		want []interface{}
+	}{
+		{
+			"uniform",
+			0,
+			[]interface{}{"3", "1", "3", "3", "2", "1", "2", "3", "2", "1"},
+		},
+		{
+			"zipf",
+			2,
+			[]interface{}{"1", "1", "1", "1", "1", "1", "3", "2", "2", "1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewReference(1, 0, testReferenced, tt.skew)
+
+			got := make([]interface{}, len(tt.want))
+			for i := range got {
+				got[i] = v.Get()
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewReference() =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_value_PreferredParamFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		v    Value
+		want int16
+	}{
+		{"reference", NewReference(1, 0, testReferenced, 0), pgtype.TextFormatCode},
+		{"int4", NewInt4(1, 0, 1, 2), pgtype.BinaryFormatCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.v.(pgtype.ParamFormatPreferrer).PreferredParamFormat(); got != tt.want {
+				t.Errorf("value.PreferredParamFormat() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
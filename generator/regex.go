@@ -0,0 +1,582 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"regexp/syntax"
+	"strings"
+	"unicode/utf8"
+)
+
+type regexString struct {
+	re        *syntax.Regexp
+	maxRepeat int
+}
+
+// RegexString returns a StringSource which generates strings matching pattern,
+// using Perl syntax as accepted by the regexp package.
+// Unbounded repetitions, such as `*`, `+` and `{n,}`, repeat up to maxRepeat times,
+// or their minimum if that is higher.
+// Any character (`.`) generates printable ASCII characters
+// and anchors or word boundaries do not generate any output.
+func RegexString(pattern string, maxRepeat int) (StringSource, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, fmt.Errorf("generator.RegexString: %w", err)
+	}
+
+	if re = withoutNUL(re); re == nil {
+		return nil, fmt.Errorf("generator.RegexString: %w", errNoMatch)
+	}
+
+	return regexString{re, maxRepeat}, nil
+}
+
+var errNoMatch = errors.New("pattern does not match any string without NUL characters")
+
+// withoutNUL returns a copy of re without the alternatives and optional repetitions
+// which only match NUL or invalid characters, or nothing at all.
+// Such repetitions repeat 0 times. It returns nil if re can not match
+// any string without NUL characters, which PostgreSQL text does not support.
+func withoutNUL(re *syntax.Regexp) *syntax.Regexp {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return nil
+
+	case syntax.OpCharClass:
+		if !hasValidRune(re.Rune) {
+			return nil
+		}
+		return re
+
+	case syntax.OpLiteral:
+		for _, c := range re.Rune {
+			if c == 0 || !utf8.ValidRune(c) {
+				return nil
+			}
+		}
+		return re
+
+	case syntax.OpCapture, syntax.OpConcat:
+		subs := make([]*syntax.Regexp, len(re.Sub))
+		for i, sub := range re.Sub {
+			if subs[i] = withoutNUL(sub); subs[i] == nil {
+				return nil
+			}
+		}
+		c := *re
+		c.Sub = subs
+		return &c
+
+	case syntax.OpAlternate:
+		var subs []*syntax.Regexp
+		for _, sub := range re.Sub {
+			if sub = withoutNUL(sub); sub != nil {
+				subs = append(subs, sub)
+			}
+		}
+		switch len(subs) {
+		case 0:
+			return nil
+		case 1:
+			return subs[0]
+		}
+		c := *re
+		c.Sub = subs
+		return &c
+
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		sub := withoutNUL(re.Sub[0])
+		if sub == nil {
+			if min, _ := repeatBounds(re); min > 0 {
+				return nil
+			}
+			return &syntax.Regexp{Op: syntax.OpEmptyMatch}
+		}
+		c := *re
+		c.Sub = []*syntax.Regexp{sub}
+		return &c
+
+	default:
+		return re
+	}
+}
+
+// hasValidRune reports if the pairs of ranges contain any valid rune other than NUL,
+// as generated by classRune.
+func hasValidRune(ranges []rune) bool {
+	for i := 0; i < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		if lo == 0 {
+			lo = 1
+		}
+		if lo <= hi && (lo < surrogateMin || hi >= surrogateMin+surrogateCount) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s regexString) string(r *rand.Rand) string {
+	var b strings.Builder
+	s.write(&b, r, s.re)
+	return b.String()
+}
+
+// repeatMax returns the upper repetition bound for an operator with
+// min and max, where a max of -1 means unbounded.
+func (s regexString) repeatMax(min, max int) int {
+	if max >= 0 {
+		return max
+	}
+	if s.maxRepeat > min {
+		return s.maxRepeat
+	}
+	return min
+}
+
+func (s regexString) write(b *strings.Builder, r *rand.Rand, re *syntax.Regexp) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, c := range re.Rune {
+			b.WriteRune(c)
+		}
+
+	case syntax.OpCharClass:
+		b.WriteRune(classRune(r, re.Rune))
+
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteRune(Printable.rune(r))
+
+	case syntax.OpCapture:
+		s.write(b, r, re.Sub[0])
+
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			s.write(b, r, sub)
+		}
+
+	case syntax.OpAlternate:
+		s.write(b, r, re.Sub[r.Intn(len(re.Sub))])
+
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := repeatBounds(re)
+		max = s.repeatMax(min, max)
+
+		for n := min + r.Intn(max-min+1); n > 0; n-- {
+			s.write(b, r, re.Sub[0])
+		}
+	}
+}
+
+// repeatBounds returns the min and max repetitions of a repeating operator.
+// A max of -1 means unbounded.
+func repeatBounds(re *syntax.Regexp) (min, max int) {
+	switch re.Op {
+	case syntax.OpStar:
+		return 0, -1
+	case syntax.OpPlus:
+		return 1, -1
+	case syntax.OpQuest:
+		return 0, 1
+	default:
+		return re.Min, re.Max
+	}
+}
+
+// classRune picks a random rune from a character class,
+// which is defined by pairs of inclusive ranges.
+// NUL and surrogates are never returned.
+func classRune(r *rand.Rand, ranges []rune) rune {
+	var total int64
+	for i := 0; i < len(ranges); i += 2 {
+		total += int64(ranges[i+1]-ranges[i]) + 1
+	}
+
+	for {
+		n := r.Int63n(total)
+
+		for i := 0; i < len(ranges); i += 2 {
+			size := int64(ranges[i+1]-ranges[i]) + 1
+			if n < size {
+				if c := ranges[i] + rune(n); c != 0 && utf8.ValidRune(c) {
+					return c
+				}
+				break
+			}
+			n -= size
+		}
+	}
+}
+
+func (s regexString) maxChars() int {
+	return s.maxCharsOf(s.re)
+}
+
+func (s regexString) maxCharsOf(re *syntax.Regexp) int {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return len(re.Rune)
+
+	case syntax.OpCharClass, syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return 1
+
+	case syntax.OpCapture:
+		return s.maxCharsOf(re.Sub[0])
+
+	case syntax.OpConcat:
+		var n int
+		for _, sub := range re.Sub {
+			n += s.maxCharsOf(sub)
+		}
+		return n
+
+	case syntax.OpAlternate:
+		var n int
+		for _, sub := range re.Sub {
+			if l := s.maxCharsOf(sub); l > n {
+				n = l
+			}
+		}
+		return n
+
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := repeatBounds(re)
+		return s.repeatMax(min, max) * s.maxCharsOf(re.Sub[0])
+
+	default:
+		return 0
+	}
+}
+
+// cardinality returns the amount of distinct strings which can be generated.
+// Up to maxEnumerated strings are counted by enumerating them.
+// Otherwise the amount is only known for unambiguous patterns
+// and nil is returned for any other pattern.
+func (s regexString) cardinality() *big.Int {
+	if set := s.enumerate(s.re); set != nil {
+		return big.NewInt(int64(len(set)))
+	}
+	if !unambiguous(s.re) {
+		return nil
+	}
+	return s.cardinalityOf(s.re)
+}
+
+// maxEnumerated is the maximum amount of strings enumerated by regexString.enumerate.
+const maxEnumerated = 1 << 16
+
+// enumerate returns the set of distinct strings which can be generated by re,
+// or nil if there are more than maxEnumerated.
+func (s regexString) enumerate(re *syntax.Regexp) map[string]struct{} {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return map[string]struct{}{string(re.Rune): {}}
+
+	case syntax.OpCharClass:
+		if classSize(re.Rune) > maxEnumerated {
+			return nil
+		}
+		set := make(map[string]struct{})
+		for i := 0; i < len(re.Rune); i += 2 {
+			for c := re.Rune[i]; c <= re.Rune[i+1]; c++ {
+				if c != 0 && utf8.ValidRune(c) {
+					set[string(c)] = struct{}{}
+				}
+			}
+		}
+		return set
+
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		set := make(map[string]struct{})
+		for c := firstPrintable; c <= lastPrintable; c++ {
+			set[string(c)] = struct{}{}
+		}
+		return set
+
+	case syntax.OpCapture:
+		return s.enumerate(re.Sub[0])
+
+	case syntax.OpConcat:
+		set := map[string]struct{}{"": {}}
+		for _, sub := range re.Sub {
+			if set = concatSets(set, s.enumerate(sub)); set == nil {
+				return nil
+			}
+		}
+		return set
+
+	case syntax.OpAlternate:
+		set := make(map[string]struct{})
+		for _, sub := range re.Sub {
+			sub := s.enumerate(sub)
+			if sub == nil {
+				return nil
+			}
+			for str := range sub {
+				set[str] = struct{}{}
+			}
+			if len(set) > maxEnumerated {
+				return nil
+			}
+		}
+		return set
+
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		sub := s.enumerate(re.Sub[0])
+		if sub == nil {
+			return nil
+		}
+
+		min, max := repeatBounds(re)
+		max = s.repeatMax(min, max)
+
+		power := map[string]struct{}{"": {}}
+		for i := 0; i < min; i++ {
+			if power = concatSets(power, sub); power == nil {
+				return nil
+			}
+		}
+
+		set := make(map[string]struct{})
+		for i := min; ; i++ {
+			for str := range power {
+				set[str] = struct{}{}
+			}
+			if len(set) > maxEnumerated {
+				return nil
+			}
+			if i == max {
+				return set
+			}
+			if power = concatSets(power, sub); power == nil {
+				return nil
+			}
+		}
+
+	default:
+		// Empty matches and anchors generate an empty string.
+		return map[string]struct{}{"": {}}
+	}
+}
+
+// concatSets returns the set of concatenations of the strings in a and b,
+// or nil if either is nil or there could be more than maxEnumerated.
+func concatSets(a, b map[string]struct{}) map[string]struct{} {
+	if a == nil || b == nil || len(a)*len(b) > maxEnumerated {
+		return nil
+	}
+
+	set := make(map[string]struct{}, len(a)*len(b))
+	for x := range a {
+		for y := range b {
+			set[x+y] = struct{}{}
+		}
+	}
+	return set
+}
+
+// unambiguous reports if re generates each string in only one way,
+// so that cardinalityOf returns the exact amount of strings.
+// It only recognizes concatenations with at most one part of variable length,
+// repetitions of a non-empty fixed length and alternations of different fixed lengths.
+func unambiguous(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpCapture:
+		return unambiguous(re.Sub[0])
+
+	case syntax.OpConcat:
+		var variable int
+		for _, sub := range re.Sub {
+			if !unambiguous(sub) {
+				return false
+			}
+			if _, ok := fixedLength(sub); !ok {
+				variable++
+			}
+		}
+		return variable <= 1
+
+	case syntax.OpAlternate:
+		lengths := make(map[int]bool, len(re.Sub))
+		for _, sub := range re.Sub {
+			n, ok := fixedLength(sub)
+			if !ok || lengths[n] || !unambiguous(sub) {
+				return false
+			}
+			lengths[n] = true
+		}
+		return true
+
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		n, ok := fixedLength(re.Sub[0])
+		return ok && n > 0 && unambiguous(re.Sub[0])
+
+	default:
+		return true
+	}
+}
+
+// fixedLength returns the amount of characters of all strings generated by re,
+// or false if they differ in length.
+func fixedLength(re *syntax.Regexp) (int, bool) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return len(re.Rune), true
+
+	case syntax.OpCharClass, syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return 1, true
+
+	case syntax.OpCapture:
+		return fixedLength(re.Sub[0])
+
+	case syntax.OpConcat:
+		var n int
+		for _, sub := range re.Sub {
+			l, ok := fixedLength(sub)
+			if !ok {
+				return 0, false
+			}
+			n += l
+		}
+		return n, true
+
+	case syntax.OpAlternate:
+		n, ok := fixedLength(re.Sub[0])
+		for _, sub := range re.Sub[1:] {
+			if l, lok := fixedLength(sub); !lok || l != n {
+				return 0, false
+			}
+		}
+		return n, ok
+
+	case syntax.OpRepeat:
+		if re.Min != re.Max {
+			return 0, false
+		}
+		n, ok := fixedLength(re.Sub[0])
+		return n * re.Min, ok
+
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest:
+		return 0, false
+
+	default:
+		return 0, true
+	}
+}
+
+// cardinalityOf returns the amount of strings which can be generated by re,
+// or nil if it is larger than math.MaxInt64.
+// Alternations and repetitions which generate the same string in
+// multiple ways are counted more than once, so the amount is only exact
+// for unambiguous patterns.
+func (s regexString) cardinalityOf(re *syntax.Regexp) *big.Int {
+	switch re.Op {
+	case syntax.OpCharClass:
+		return big.NewInt(classSize(re.Rune))
+
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return big.NewInt(Printable.size())
+
+	case syntax.OpCapture:
+		return s.cardinalityOf(re.Sub[0])
+
+	case syntax.OpConcat:
+		n := big.NewInt(1)
+		for _, sub := range re.Sub {
+			c := s.cardinalityOf(sub)
+			if c == nil {
+				return nil
+			}
+			if n = limitCardinality(n.Mul(n, c)); n == nil {
+				return nil
+			}
+		}
+		return n
+
+	case syntax.OpAlternate:
+		n := new(big.Int)
+		for _, sub := range re.Sub {
+			c := s.cardinalityOf(sub)
+			if c == nil {
+				return nil
+			}
+			if n = limitCardinality(n.Add(n, c)); n == nil {
+				return nil
+			}
+		}
+		return n
+
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		c := s.cardinalityOf(re.Sub[0])
+		if c == nil {
+			return nil
+		}
+
+		min, max := repeatBounds(re)
+		max = s.repeatMax(min, max)
+
+		n := new(big.Int)
+		power := new(big.Int).Exp(c, big.NewInt(int64(min)), nil)
+
+		for i := min; i <= max; i++ {
+			if n = limitCardinality(n.Add(n, power)); n == nil {
+				return nil
+			}
+			power.Mul(power, c)
+		}
+		return n
+
+	default:
+		// Literals, empty matches and anchors generate a single string.
+		return big.NewInt(1)
+	}
+}
+
+// classSize returns the amount of runes in a character class
+// which can be returned by classRune.
+func classSize(ranges []rune) int64 {
+	var n int64
+	for i := 0; i < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		n += int64(hi-lo) + 1
+
+		if lo == 0 {
+			n--
+		}
+		// Overlap with the surrogate range.
+		sLo, sHi := lo, hi
+		if sLo < surrogateMin {
+			sLo = surrogateMin
+		}
+		if sHi > surrogateMin+surrogateCount-1 {
+			sHi = surrogateMin + surrogateCount - 1
+		}
+		if sLo <= sHi {
+			n -= int64(sHi-sLo) + 1
+		}
+	}
+	return n
+}
@@ -0,0 +1,187 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"math/rand"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestRegexString(t *testing.T) {
+	tests := []struct {
+		name      string
+		pattern   string
+		maxRepeat int
+		want      []string
+		wantLen   int
+		wantErr   bool
+	}{
+		{
+			"Syntax error",
+			`[`,
+			3,
+			nil,
+			0,
+			true,
+		},
+		{
+			"No match",
+			`a[^\x00-\x{10FFFF}]`,
+			3,
+			nil,
+			0,
+			true,
+		},
+		{
+			"NUL class",
+			`a[\x00]`,
+			3,
+			nil,
+			0,
+			true,
+		},
+		{
+			"NUL literal",
+			`a\x00`,
+			3,
+			nil,
+			0,
+			true,
+		},
+		{
+			"NUL alternatives",
+			`ab|\x00|c\x00`,
+			3,
+			[]string{"ab", "ab"},
+			2,
+			false,
+		},
+		{
+			"Optional NUL",
+			`(\x00)?b\x00*`,
+			3,
+			[]string{"b", "b"},
+			1,
+			false,
+		},
+		{
+			"Required NUL repeat",
+			`b(\x00|[^\x00-\x{10FFFF}]){1,2}`,
+			3,
+			nil,
+			0,
+			true,
+		},
+		{
+			"Surrogate class",
+			`[\x{D800}-\x{DFFF}]`,
+			3,
+			nil,
+			0,
+			true,
+		},
+		{
+			"NUL in class",
+			`[\x00-\x01]`,
+			3,
+			[]string{"\x01", "\x01"},
+			1,
+			false,
+		},
+		{
+			"Order number",
+			`[A-Z]{3}-[0-9]{6}`,
+			3,
+			[]string{"JNN-088694", "MOJ-810136"},
+			10,
+			false,
+		},
+		{
+			"Alternate and star",
+			`^(foo|bar)+\.x*$`,
+			3,
+			[]string{"barbarbar.x", "bar."},
+			13,
+			false,
+		},
+		{
+			"Any char and minimum repeat",
+			`(?i)abc.?\d{2,}`,
+			3,
+			[]string{"ABCT170", "ABC 94"},
+			7,
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := RegexString(tt.pattern, tt.maxRepeat)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RegexString() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+
+			r := rand.New(rand.NewSource(1))
+			got := make([]string, len(tt.want))
+			for i := range got {
+				got[i] = s.string(r)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("regexString.string() =\n%q\nwant\n%q", got, tt.want)
+			}
+			if gotLen := MaxLength(s); gotLen != tt.wantLen {
+				t.Errorf("MaxLength() = %d, want %d", gotLen, tt.wantLen)
+			}
+		})
+	}
+}
+
+func TestRegexString_matches(t *testing.T) {
+	patterns := []string{
+		`[A-Z]{3}-[0-9]{6}`,
+		`^[1-9][0-9]{3} ?[A-Z]{2}$`,
+		`^(foo|ba[rz])+(\.[a-z0-9_]*)?$`,
+		`^[^a-z]{1,4}$`,
+		`^\w+@\w+\.(com|org)$`,
+	}
+
+	r := rand.New(rand.NewSource(1))
+
+	for _, p := range patterns {
+		s, err := RegexString(p, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		re := regexp.MustCompile(p)
+
+		for i := 0; i < 100; i++ {
+			if got := s.string(r); !re.MatchString(got) {
+				t.Errorf("regexString.string() = %q, does not match %s", got, p)
+			}
+		}
+	}
+}
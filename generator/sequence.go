@@ -0,0 +1,92 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"math/big"
+	"math/rand"
+)
+
+// intGenerator generates integers for integer and time based types.
+type intGenerator interface {
+	// get the next integer.
+	get() int64
+	// skip the next integer, when a null is generated instead.
+	skip()
+}
+
+// skipper is implemented by values which need to keep track of nulls,
+// generated in place of their own values.
+type skipper interface {
+	skip()
+}
+
+// sequence is a deterministic generator of integers start + n*step,
+// where n is the amount of previously generated or skipped integers.
+// Each value is shifted by a pseudo-random jitter between
+// -jitter and +jitter, inclusive. The jitter does not accumulate.
+type sequence struct {
+	rand                *rand.Rand
+	start, step, jitter int64
+	n                   int64
+}
+
+// newSequence returns a sequence, with the random source for the jitter
+// initialized with seed. Jitter must not be negative.
+func newSequence(seed, start, step, jitter int64) *sequence {
+	return &sequence{
+		rand: rand.New(
+			rand.NewSource(seed),
+		),
+		start:  start,
+		step:   step,
+		jitter: jitter,
+	}
+}
+
+// get the next integer in the sequence.
+func (s *sequence) get() int64 {
+	v := s.start + s.n*s.step
+	s.n++
+
+	if s.jitter > 0 {
+		v += s.rand.Int63n(2*s.jitter+1) - s.jitter
+	}
+
+	return v
+}
+
+// cardinality is only known for a sequence with a step of 0,
+// which consists of the values within the jitter.
+// Otherwise new values can be generated by each step.
+func (s *sequence) cardinality() *big.Int {
+	if s.step != 0 {
+		return nil
+	}
+	n := new(big.Int).Mul(big.NewInt(s.jitter), big.NewInt(2))
+	return limitCardinality(n.Add(n, big.NewInt(1)))
+}
+
+// skip the next integer in the sequence,
+// so that nulls do not shift the sequence.
+func (s *sequence) skip() {
+	s.n++
+}
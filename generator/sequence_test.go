@@ -0,0 +1,132 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_sequence(t *testing.T) {
+	tests := []struct {
+		name string
+		s    *sequence
+		skip []bool
+		want []int64
+	}{
+		{
+			"no jitter",
+			newSequence(1, 1, 1, 0),
+			make([]bool, 5),
+			[]int64{1, 2, 3, 4, 5},
+		},
+		{
+			"negative step",
+			newSequence(1, 0, -10, 0),
+			make([]bool, 3),
+			[]int64{0, -10, -20},
+		},
+		{
+			"jitter",
+			newSequence(1, 10, 5, 2),
+			make([]bool, 5),
+			[]int64{8, 14, 19, 24, 30},
+		},
+		{
+			"skip",
+			newSequence(1, 1, 1, 0),
+			[]bool{false, true, true, false, false},
+			[]int64{1, 0, 0, 4, 5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]int64, len(tt.want))
+
+			for i, skip := range tt.skip {
+				if skip {
+					tt.s.skip()
+					continue
+				}
+				got[i] = tt.s.get()
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sequence.get() =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_sequence_nulls(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		v    Value
+		want []interface{}
+	}{
+		{
+			"int2",
+			NewInt2Sequence(1, 50, 1, 1, 0),
+			[]interface{}{int16(1), int16(2), int16(3), nil, nil, int16(6), nil},
+		},
+		{
+			"int4",
+			NewInt4Sequence(1, 50, 1, 1, 0),
+			[]interface{}{int32(1), int32(2), int32(3), nil, nil, int32(6), nil},
+		},
+		{
+			"int8",
+			NewInt8Sequence(1, 50, 1, 1, 0),
+			[]interface{}{int64(1), int64(2), int64(3), nil, nil, int64(6), nil},
+		},
+		{
+			"timestamp",
+			NewTimestampSequence(1, 50, start, time.Hour, 0),
+			[]interface{}{start, start.Add(time.Hour), start.Add(2 * time.Hour), nil, nil, start.Add(5 * time.Hour), nil},
+		},
+		{
+			"timestamptz",
+			NewTimestamptzSequence(1, 50, start, -time.Hour, 0),
+			[]interface{}{start, start.Add(-time.Hour), start.Add(-2 * time.Hour), nil, nil, start.Add(-5 * time.Hour), nil},
+		},
+		{
+			"date",
+			NewDateSequence(1, 50, start.Add(10*time.Hour), 7, 0),
+			[]interface{}{start, start.Add(7 * Day), start.Add(14 * Day), nil, nil, start.Add(35 * Day), nil},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]interface{}, len(tt.want))
+
+			for i := range got {
+				got[i] = tt.v.Get()
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("valueGenerator.Get() =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
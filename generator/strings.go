@@ -0,0 +1,146 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"math/big"
+	"math/rand"
+	"strings"
+)
+
+// Charset from which RandomString picks characters.
+type Charset int
+
+// Supported character sets.
+const (
+	Alphanumeric Charset = iota // Latin letters and digits.
+	Hex                         // Lower case hexadecimal digits.
+	Printable                   // Printable ASCII characters, including space.
+	Unicode                     // All Unicode code points, except NUL and surrogates.
+)
+
+const (
+	alphanumericChars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	hexChars          = "0123456789abcdef"
+
+	firstPrintable = ' '
+	lastPrintable  = '~'
+
+	maxRune        = '\U0010FFFF'
+	surrogateMin   = 0xD800
+	surrogateCount = 0x800
+)
+
+// rune returns a random character from the Charset.
+func (cs Charset) rune(r *rand.Rand) rune {
+	switch cs {
+	case Hex:
+		return rune(hexChars[r.Intn(len(hexChars))])
+	case Printable:
+		return firstPrintable + rune(r.Intn(lastPrintable-firstPrintable+1))
+	case Unicode:
+		// Skip NUL, which PostgreSQL does not accept in text.
+		c := 1 + rune(r.Int31n(maxRune-surrogateCount))
+		if c >= surrogateMin {
+			c += surrogateCount
+		}
+		return c
+	default:
+		return rune(alphanumericChars[r.Intn(len(alphanumericChars))])
+	}
+}
+
+// size returns the amount of characters in the Charset.
+func (cs Charset) size() int64 {
+	switch cs {
+	case Hex:
+		return int64(len(hexChars))
+	case Printable:
+		return lastPrintable - firstPrintable + 1
+	case Unicode:
+		return maxRune - surrogateCount
+	default:
+		return int64(len(alphanumericChars))
+	}
+}
+
+// StringSource generates pseudo-random strings.
+type StringSource interface {
+	// string returns the next string, using r as source.
+	string(r *rand.Rand) string
+	// maxChars returns the maximum length of generated strings,
+	// in characters.
+	maxChars() int
+}
+
+// MaxLength returns the maximum amount of characters in strings
+// generated by source.
+func MaxLength(source StringSource) int {
+	return source.maxChars()
+}
+
+type randomString struct {
+	minLen, maxLen int
+	charset        Charset
+}
+
+// RandomString returns a StringSource which generates strings of
+// minLen up to and including maxLen characters from charset.
+// Lengths are counted in characters (runes), not bytes.
+// MinLen must not be greater than maxLen.
+func RandomString(minLen, maxLen int, charset Charset) StringSource {
+	return randomString{minLen, maxLen, charset}
+}
+
+func (s randomString) string(r *rand.Rand) string {
+	n := s.minLen + r.Intn(s.maxLen-s.minLen+1)
+
+	var b strings.Builder
+	b.Grow(n)
+
+	for i := 0; i < n; i++ {
+		b.WriteRune(s.charset.rune(r))
+	}
+
+	return b.String()
+}
+
+func (s randomString) maxChars() int {
+	return s.maxLen
+}
+
+// cardinality returns the sum of the amount of strings of each length.
+func (s randomString) cardinality() *big.Int {
+	var (
+		size  = big.NewInt(s.charset.size())
+		n     = new(big.Int)
+		power = new(big.Int).Exp(size, big.NewInt(int64(s.minLen)), nil)
+	)
+
+	for l := s.minLen; l <= s.maxLen; l++ {
+		if n.Add(n, power).Cmp(maxCardinality) > 0 {
+			return nil
+		}
+		power.Mul(power, size)
+	}
+
+	return n
+}
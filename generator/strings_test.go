@@ -0,0 +1,81 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"math/rand"
+	"testing"
+	"unicode/utf8"
+)
+
+func Test_randomString(t *testing.T) {
+	tests := []struct {
+		name   string
+		source StringSource
+		want   string
+	}{
+		{
+			"Empty",
+			RandomString(0, 0, Alphanumeric),
+			"",
+		},
+		{
+			"Alphanumeric",
+			RandomString(3, 8, Alphanumeric),
+			"FbD56TI2",
+		},
+		{
+			"Hex",
+			RandomString(3, 8, Hex),
+			"f7b169c8",
+		},
+		{
+			"Printable",
+			RandomString(3, 8, Printable),
+			"TO$:(k l",
+		},
+		{
+			"Unicode",
+			RandomString(3, 8, Unicode),
+			"\U00036928\U000572cb\U00047809⢶\U00054eb5\U000cc3b8\U00044bdb\U00046004",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := rand.New(rand.NewSource(1))
+
+			if got := tt.source.string(r); got != tt.want {
+				t.Errorf("randomString.string() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCharset_rune(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 10000; i++ {
+		c := Unicode.rune(r)
+		if c == 0 || !utf8.ValidRune(c) {
+			t.Fatalf("Charset.rune() = %U, want valid non-NUL rune", c)
+		}
+	}
+}
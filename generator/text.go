@@ -0,0 +1,118 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"math/big"
+	"math/rand"
+
+	"github.com/jackc/pgtype"
+)
+
+type textType struct {
+	pgtype.Text
+	rand   *rand.Rand
+	source StringSource
+}
+
+func (t *textType) cardinality() *big.Int {
+	return cardinalityOf(t.source)
+}
+
+func (t *textType) NextValue() {
+	t.Text.String = t.source.string(t.rand)
+	t.Text.Status = pgtype.Present
+}
+
+// NewText returns a text value generator.
+// Values are obtained from source.
+func NewText(seed int64, nullProbabilty float32, source StringSource) Value {
+	return &value{
+		Value: &textType{
+			rand: rand.New(
+				rand.NewSource(seed),
+			),
+			source: source,
+		},
+		nulls: newNull(seed, nullProbabilty),
+	}
+}
+
+type varcharType struct {
+	pgtype.Varchar
+	rand   *rand.Rand
+	source StringSource
+}
+
+func (t *varcharType) cardinality() *big.Int {
+	return cardinalityOf(t.source)
+}
+
+func (t *varcharType) NextValue() {
+	t.Varchar.String = t.source.string(t.rand)
+	t.Varchar.Status = pgtype.Present
+}
+
+// NewVarchar returns a character varying value generator.
+// Values are obtained from source, which should not generate
+// strings longer than the column's length limit.
+func NewVarchar(seed int64, nullProbabilty float32, source StringSource) Value {
+	return &value{
+		Value: &varcharType{
+			rand: rand.New(
+				rand.NewSource(seed),
+			),
+			source: source,
+		},
+		nulls: newNull(seed, nullProbabilty),
+	}
+}
+
+type bpcharType struct {
+	pgtype.BPChar
+	rand   *rand.Rand
+	source StringSource
+}
+
+func (t *bpcharType) cardinality() *big.Int {
+	return cardinalityOf(t.source)
+}
+
+func (t *bpcharType) NextValue() {
+	t.BPChar.String = t.source.string(t.rand)
+	t.BPChar.Status = pgtype.Present
+}
+
+// NewBPChar returns a fixed length, blank padded character value generator.
+// Values are obtained from source, which should not generate
+// strings longer than the column's length.
+// Shorter values are padded by PostgreSQL.
+func NewBPChar(seed int64, nullProbabilty float32, source StringSource) Value {
+	return &value{
+		Value: &bpcharType{
+			rand: rand.New(
+				rand.NewSource(seed),
+			),
+			source: source,
+		},
+		nulls: newNull(seed, nullProbabilty),
+	}
+}
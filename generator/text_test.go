@@ -0,0 +1,62 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_textTypes(t *testing.T) {
+	tests := []struct {
+		name string
+		v    Value
+		want interface{}
+	}{
+		{
+			"text null",
+			NewText(1, 100, RandomString(5, 5, Alphanumeric)),
+			nil,
+		},
+		{
+			"text",
+			NewText(3, 0, RandomString(5, 5, Alphanumeric)),
+			"9aIh3",
+		},
+		{
+			"varchar",
+			NewVarchar(3, 0, RandomString(5, 5, Hex)),
+			"90a1b",
+		},
+		{
+			"bpchar",
+			NewBPChar(3, 0, RandomString(1, 3, Printable)),
+			"c0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.v.Get(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("valueGenerator.Get() = %T(%v), want %T(%v)", got, got, tt.want, tt.want)
+			}
+		})
+	}
+}
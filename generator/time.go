@@ -0,0 +1,292 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/jackc/pgtype"
+)
+
+// Day is a granularity of 24 hours, aligned to midnight UTC.
+const Day = 24 * time.Hour
+
+const microsPerSecond = int64(time.Second / time.Microsecond)
+
+// floorDiv divides a by b (b > 0), rounding towards negative infinity.
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && a < 0 {
+		q--
+	}
+	return q
+}
+
+// ceilDiv divides a by b (b > 0), rounding towards positive infinity.
+func ceilDiv(a, b int64) int64 {
+	return -floorDiv(-a, b)
+}
+
+// unixMicro returns t as microseconds since the Unix epoch.
+func unixMicro(t time.Time) int64 {
+	return t.Unix()*microsPerSecond + int64(t.Nanosecond())/int64(time.Microsecond)
+}
+
+// fromUnixMicro returns the UTC time of us microseconds since the Unix epoch.
+func fromUnixMicro(us int64) time.Time {
+	sec := floorDiv(us, microsPerSecond)
+	return time.Unix(sec, (us-sec*microsPerSecond)*int64(time.Microsecond)).UTC()
+}
+
+// micros returns d in whole microseconds, and at least 1.
+func micros(d time.Duration) int64 {
+	if us := int64(d / time.Microsecond); us > 0 {
+		return us
+	}
+	return 1
+}
+
+// granularRange returns an intRange, generating multiples of granularity
+// between min and max, all in microseconds.
+func granularRange(seed, min, max, granularity int64) *intRange {
+	return newIntRange(seed, ceilDiv(min, granularity), floorDiv(max, granularity))
+}
+
+type timestampType struct {
+	pgtype.Timestamp
+	generator   intGenerator
+	granularity int64
+}
+
+func (t *timestampType) skip() {
+	t.generator.skip()
+}
+
+func (t *timestampType) cardinality() *big.Int {
+	return cardinalityOf(t.generator)
+}
+
+func (t *timestampType) NextValue() {
+	t.Timestamp.Time = fromUnixMicro(t.generator.get() * t.granularity)
+	t.Timestamp.InfinityModifier = pgtype.None
+	t.Timestamp.Status = pgtype.Present
+}
+
+// NewTimestamp returns a timestamp (without time zone) value generator.
+//
+// Values are uniformly distributed between min and max, inclusive,
+// as multiples of granularity since the Unix epoch.
+// The wall clock of values is that of min and max in UTC.
+// There must be at least one multiple of granularity between min and max.
+func NewTimestamp(seed int64, nullProbabilty float32, min, max time.Time, granularity time.Duration) Value {
+	g := micros(granularity)
+
+	return &value{
+		Value: &timestampType{
+			generator:   granularRange(seed, unixMicro(min), unixMicro(max), g),
+			granularity: g,
+		},
+		nulls: newNull(seed, nullProbabilty),
+	}
+}
+
+type timestamptzType struct {
+	pgtype.Timestamptz
+	generator   intGenerator
+	granularity int64
+}
+
+func (t *timestamptzType) skip() {
+	t.generator.skip()
+}
+
+func (t *timestamptzType) cardinality() *big.Int {
+	return cardinalityOf(t.generator)
+}
+
+func (t *timestamptzType) NextValue() {
+	t.Timestamptz.Time = fromUnixMicro(t.generator.get() * t.granularity)
+	t.Timestamptz.InfinityModifier = pgtype.None
+	t.Timestamptz.Status = pgtype.Present
+}
+
+// NewTimestamptz returns a timestamp with time zone value generator.
+//
+// Values are uniformly distributed between min and max, inclusive,
+// as multiples of granularity since the Unix epoch.
+// There must be at least one multiple of granularity between min and max.
+func NewTimestamptz(seed int64, nullProbabilty float32, min, max time.Time, granularity time.Duration) Value {
+	g := micros(granularity)
+
+	return &value{
+		Value: &timestamptzType{
+			generator:   granularRange(seed, unixMicro(min), unixMicro(max), g),
+			granularity: g,
+		},
+		nulls: newNull(seed, nullProbabilty),
+	}
+}
+
+type dateType struct {
+	pgtype.Date
+	generator intGenerator
+}
+
+func (d *dateType) skip() {
+	d.generator.skip()
+}
+
+func (d *dateType) cardinality() *big.Int {
+	return cardinalityOf(d.generator)
+}
+
+func (d *dateType) NextValue() {
+	d.Date.Time = fromUnixMicro(d.generator.get() * micros(Day))
+	d.Date.InfinityModifier = pgtype.None
+	d.Date.Status = pgtype.Present
+}
+
+// NewDate returns a date value generator.
+//
+// Values are uniformly distributed between the dates of min and max, inclusive.
+// Only the year, month and day of min and max are used,
+// in their own location.
+func NewDate(seed int64, nullProbabilty float32, min, max time.Time) Value {
+	return &value{
+		Value: &dateType{
+			generator: newIntRange(seed, dayNumber(min), dayNumber(max)),
+		},
+		nulls: newNull(seed, nullProbabilty),
+	}
+}
+
+// dayNumber returns the amount of days between the Unix epoch and the date of t.
+func dayNumber(t time.Time) int64 {
+	y, m, d := t.Date()
+	return floorDiv(unixMicro(time.Date(y, m, d, 0, 0, 0, 0, time.UTC)), micros(Day))
+}
+
+// NewTimestampSequence returns a timestamp (without time zone) value generator,
+// which generates the sequence start + n*step, where n is the row number
+// starting at 0. Rows for which a null is generated still count for n.
+// Each value is shifted by a random jitter between -jitter and +jitter, inclusive.
+// Step and jitter are rounded down to microseconds. Jitter must not be negative.
+func NewTimestampSequence(seed int64, nullProbabilty float32, start time.Time, step, jitter time.Duration) Value {
+	return &value{
+		Value: &timestampType{
+			generator:   newSequence(seed, unixMicro(start), int64(step/time.Microsecond), int64(jitter/time.Microsecond)),
+			granularity: 1,
+		},
+		nulls: newNull(seed, nullProbabilty),
+	}
+}
+
+// NewTimestamptzSequence returns a timestamp with time zone value generator,
+// with the same behavior as NewTimestampSequence.
+func NewTimestamptzSequence(seed int64, nullProbabilty float32, start time.Time, step, jitter time.Duration) Value {
+	return &value{
+		Value: &timestamptzType{
+			generator:   newSequence(seed, unixMicro(start), int64(step/time.Microsecond), int64(jitter/time.Microsecond)),
+			granularity: 1,
+		},
+		nulls: newNull(seed, nullProbabilty),
+	}
+}
+
+// NewDateSequence returns a date value generator,
+// which generates the sequence start + n*step days, like NewTimestampSequence.
+// Only the year, month and day of start are used.
+func NewDateSequence(seed int64, nullProbabilty float32, start time.Time, step, jitter int) Value {
+	return &value{
+		Value: &dateType{
+			generator: newSequence(seed, dayNumber(start), int64(step), int64(jitter)),
+		},
+		nulls: newNull(seed, nullProbabilty),
+	}
+}
+
+type timeType struct {
+	pgtype.Time
+	generator   intGenerator
+	granularity int64
+}
+
+func (t *timeType) cardinality() *big.Int {
+	return cardinalityOf(t.generator)
+}
+
+func (t *timeType) NextValue() {
+	t.Time.Microseconds = t.generator.get() * t.granularity
+	t.Time.Status = pgtype.Present
+}
+
+// NewTime returns a time of day (without time zone) value generator.
+//
+// Min and max are durations since midnight and values are uniformly
+// distributed between them, inclusive, as multiples of granularity.
+// Min must not be negative and max must not exceed 24 hours.
+// There must be at least one multiple of granularity between min and max.
+func NewTime(seed int64, nullProbabilty float32, min, max, granularity time.Duration) Value {
+	g := micros(granularity)
+
+	return &value{
+		Value: &timeType{
+			generator:   granularRange(seed, int64(min/time.Microsecond), int64(max/time.Microsecond), g),
+			granularity: g,
+		},
+		nulls: newNull(seed, nullProbabilty),
+	}
+}
+
+type intervalType struct {
+	pgtype.Interval
+	generator   intGenerator
+	granularity int64
+}
+
+func (i *intervalType) cardinality() *big.Int {
+	return cardinalityOf(i.generator)
+}
+
+func (i *intervalType) NextValue() {
+	i.Interval.Microseconds = i.generator.get() * i.granularity
+	i.Interval.Days = 0
+	i.Interval.Months = 0
+	i.Interval.Status = pgtype.Present
+}
+
+// NewInterval returns an interval value generator.
+//
+// Values are uniformly distributed between min and max, inclusive,
+// as multiples of granularity.
+// There must be at least one multiple of granularity between min and max.
+func NewInterval(seed int64, nullProbabilty float32, min, max, granularity time.Duration) Value {
+	g := micros(granularity)
+
+	return &value{
+		Value: &intervalType{
+			generator:   granularRange(seed, int64(min/time.Microsecond), int64(max/time.Microsecond), g),
+			granularity: g,
+		},
+		nulls: newNull(seed, nullProbabilty),
+	}
+}
@@ -0,0 +1,134 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_floorDiv_ceilDiv(t *testing.T) {
+	tests := []struct {
+		a, b        int64
+		floor, ceil int64
+	}{
+		{-1, 2, -1, 0},
+		{-2, 2, -1, -1},
+		{3, 2, 1, 2},
+		{4, 2, 2, 2},
+	}
+	for _, tt := range tests {
+		if got := floorDiv(tt.a, tt.b); got != tt.floor {
+			t.Errorf("floorDiv(%d, %d) = %d, want %d", tt.a, tt.b, got, tt.floor)
+		}
+		if got := ceilDiv(tt.a, tt.b); got != tt.ceil {
+			t.Errorf("ceilDiv(%d, %d) = %d, want %d", tt.a, tt.b, got, tt.ceil)
+		}
+	}
+}
+
+func Test_unixMicro(t *testing.T) {
+	for _, want := range []int64{-1500001, -1, 0, 1, 1632300000123456} {
+		if got := unixMicro(fromUnixMicro(want)); got != want {
+			t.Errorf("unixMicro(fromUnixMicro(%d)) = %d", want, got)
+		}
+	}
+}
+
+func Test_timeTypes(t *testing.T) {
+	min := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	max := time.Date(2021, 12, 31, 23, 59, 59, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		v    Value
+		want interface{}
+	}{
+		{
+			"timestamp null",
+			NewTimestamp(1, 100, min, max, time.Second),
+			nil,
+		},
+		{
+			"timestamp",
+			NewTimestamp(3, 0, min, max, time.Second),
+			time.Date(2021, 9, 22, 8, 57, 41, 0, time.UTC),
+		},
+		{
+			"timestamp single value",
+			NewTimestamp(3, 0, min, min.Add(time.Minute-1), time.Minute),
+			min,
+		},
+		{
+			"timestamptz day",
+			NewTimestamptz(3, 0, min, max, Day),
+			time.Date(2021, 6, 11, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			"timestamptz before epoch",
+			NewTimestamptz(3, 0, min.Add(-100*365*Day), max, time.Minute),
+			time.Date(1945, 7, 6, 9, 41, 0, 0, time.UTC),
+		},
+		{
+			"date",
+			NewDate(3, 0, min, max),
+			time.Date(2021, 6, 11, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.v.Get(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("valueGenerator.Get() = %T(%v), want %T(%v)", got, got, tt.want, tt.want)
+			}
+		})
+	}
+}
+
+func Test_timeTypes_EncodeText(t *testing.T) {
+	tests := []struct {
+		name string
+		v    Value
+		want string
+	}{
+		{
+			"time",
+			NewTime(3, 0, 8*time.Hour, 17*time.Hour, 15*time.Minute),
+			"13:45:00.000000",
+		},
+		{
+			"interval",
+			NewInterval(3, 0, -time.Hour, 48*time.Hour, time.Second),
+			"15:05:39.000000",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf, err := tt.v.EncodeText(nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(buf); got != tt.want {
+				t.Errorf("valueGenerator.EncodeText() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
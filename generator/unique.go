@@ -0,0 +1,125 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// cardinaler is implemented by values, generators and string sources,
+// which generate a limited amount of distinct values.
+type cardinaler interface {
+	// cardinality returns the amount of distinct values which can be generated,
+	// or nil if it is larger than math.MaxInt64.
+	cardinality() *big.Int
+}
+
+var maxCardinality = big.NewInt(math.MaxInt64)
+
+// limitCardinality returns n, or nil if n is nil or larger than math.MaxInt64.
+func limitCardinality(n *big.Int) *big.Int {
+	if n == nil || n.Cmp(maxCardinality) > 0 {
+		return nil
+	}
+	return n
+}
+
+// cardinalityOf returns the cardinality of x,
+// or nil if x does not implement cardinaler.
+func cardinalityOf(x interface{}) *big.Int {
+	if c, ok := x.(cardinaler); ok {
+		return c.cardinality()
+	}
+	return nil
+}
+
+// Cardinality returns the amount of distinct non-null values v can generate.
+// Nil is returned if the amount is unknown, unlimited or larger than math.MaxInt64.
+func Cardinality(v Value) *big.Int {
+	if w, ok := v.(*value); ok {
+		return cardinalityOf(w.Value)
+	}
+	return cardinalityOf(v)
+}
+
+type unique struct {
+	Value
+	seen map[string]struct{}
+}
+
+// uniqueAttempts is the amount of attempts for each value already generated,
+// after which unique gives up on finding a new value.
+const uniqueAttempts = 100
+
+func (u *unique) skip() {
+	if s, ok := u.Value.(skipper); ok {
+		s.skip()
+	}
+}
+
+// NextValue keeps generating values until one is found
+// which was not seen before. It panics when all attempts fail.
+func (u *unique) NextValue() {
+	var buf []byte
+
+	for i := uniqueAttempts * (len(u.seen) + 1); i > 0; i-- {
+		u.Value.NextValue()
+
+		var err error
+		if buf, err = u.Value.EncodeText(nil, buf[:0]); err != nil {
+			panic(fmt.Errorf("generator.unique: %w", err))
+		}
+
+		if _, ok := u.seen[string(buf)]; !ok {
+			u.seen[string(buf)] = struct{}{}
+			return
+		}
+	}
+
+	panic(fmt.Errorf("generator.unique: no new value found after %d distinct values", len(u.seen)))
+}
+
+func (u *unique) cardinality() *big.Int {
+	return cardinalityOf(u.Value)
+}
+
+// NewUnique returns a value generator which only generates
+// distinct values from v, by retrying when a value was generated before.
+// Nulls, if any, are generated as by v and are not considered duplicates.
+// The value generator panics when too many attempts are needed
+// to find a new value, which happens when the values of v are exhausted.
+// Use Cardinality to check upfront.
+func NewUnique(v Value) Value {
+	w, ok := v.(*value)
+	if !ok {
+		w = &value{Value: v}
+	}
+
+	return &value{
+		Value: &unique{
+			Value: w.Value,
+			seen:  make(map[string]struct{}),
+		},
+		nulls: w.nulls,
+	}
+}
@@ -0,0 +1,147 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func mustRegexString(pattern string) StringSource {
+	src, err := RegexString(pattern, 3)
+	if err != nil {
+		panic(err)
+	}
+	return src
+}
+
+func TestCardinality(t *testing.T) {
+	day := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		v    Value
+		want *big.Int
+	}{
+		{"bool", NewBool(1, 0, 50), big.NewInt(2)},
+		{"bool only true", NewBool(1, 0, 100), big.NewInt(1)},
+		{"int2", NewInt2(1, 0, -10, 10), big.NewInt(21)},
+		{"int8 full range", NewInt8(1, 0, math.MinInt64, math.MaxInt64), nil},
+		{"int4 sequence", NewInt4Sequence(1, 0, 1, 1, 0), nil},
+		{"int4 sequence without step", NewInt4Sequence(1, 0, 1, 0, 3), big.NewInt(7)},
+		{"int4 choice", NewInt4Choice(1, 0, []int32{1, 2, 2, 3}, []float64{1, 1, 1, 0}), big.NewInt(2)},
+		{"numeric", NewNumeric(1, 0, big.NewInt(-100), big.NewInt(100), 2), big.NewInt(201)},
+		{"float8", NewFloat8(1, 0, Uniform(0, 1)), nil},
+		{"text", NewText(1, 0, RandomString(0, 2, Hex)), big.NewInt(1 + 16 + 256)},
+		{"text too many", NewText(1, 0, RandomString(0, 100, Alphanumeric)), nil},
+		{"varchar choice", NewVarchar(1, 0, ChoiceString([]string{"a", "b"}, nil)), big.NewInt(2)},
+		{"char regex", NewBPChar(1, 0, mustRegexString("[A-C]{2}-(x|yz)?")), big.NewInt(27)},
+		{"text regex repeat", NewText(1, 0, mustRegexString("a*")), big.NewInt(4)},
+		{"text regex too many", NewText(1, 0, mustRegexString(".{100}")), nil},
+		{"text regex ambiguous", NewText(1, 0, mustRegexString("a?a?")), big.NewInt(3)},
+		{"text regex ambiguous alternate", NewText(1, 0, mustRegexString("(ab|a)(bc|c)")), big.NewInt(3)},
+		{"text regex ambiguous too many", NewText(1, 0, mustRegexString("[a-z]*[a-z]*")), nil},
+		{"text regex unambiguous", NewText(1, 0, mustRegexString("[a-z]{2}-[0-9]*")), big.NewInt(26 * 26 * 1111)},
+		{"text regex NUL alternative", NewText(1, 0, mustRegexString(`[a-c]|\x00`)), big.NewInt(3)},
+		{"text regex optional NUL", NewText(1, 0, mustRegexString(`[a-c](\x00)*`)), big.NewInt(3)},
+		{"date", NewDate(1, 0, day, day.AddDate(0, 0, 9)), big.NewInt(10)},
+		{"timestamp", NewTimestamp(1, 0, day, day.Add(time.Hour), time.Minute), big.NewInt(61)},
+		{"time", NewTime(1, 0, 0, time.Hour, time.Hour), big.NewInt(2)},
+		{"interval", NewInterval(1, 0, 0, time.Hour, time.Minute), big.NewInt(61)},
+		{"uuid", NewUUIDv4(1, 0), nil},
+		{"reference", NewReference(1, 0, testReferenced, 0), big.NewInt(3)},
+		{"unique", NewUnique(NewInt2(1, 0, 1, 5)), big.NewInt(5)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Cardinality(tt.v); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Cardinality() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_classSize(t *testing.T) {
+	tests := []struct {
+		name   string
+		ranges []rune
+		want   int64
+	}{
+		{"letters", []rune{'A', 'Z', 'a', 'z'}, 52},
+		{"NUL", []rune{0, 9}, 9},
+		{"surrogates", []rune{0xD000, 0xDFFF}, 0x800},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classSize(tt.ranges); got != tt.want {
+				t.Errorf("classSize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewUnique(t *testing.T) {
+	v := NewUnique(NewInt2(1, 0, 1, 10))
+
+	got := make(map[interface{}]bool)
+	for i := 0; i < 10; i++ {
+		x := v.Get()
+		if got[x] {
+			t.Fatalf("NewUnique() duplicate value %v", x)
+		}
+		got[x] = true
+	}
+
+	err := func() (err error) {
+		defer func() { err, _ = recover().(error) }()
+		v.Get()
+		return nil
+	}()
+	if err == nil {
+		t.Error("NewUnique() exhausted values did not panic")
+	}
+}
+
+func TestNewUnique_nulls(t *testing.T) {
+	v := NewUnique(NewInt2(1, 50, 1, 20))
+
+	var nulls int
+	got := make(map[interface{}]bool)
+
+	for i := 0; i < 20; i++ {
+		x := v.Get()
+		if x == nil {
+			nulls++
+			continue
+		}
+		if got[x] {
+			t.Fatalf("NewUnique() duplicate value %v", x)
+		}
+		got[x] = true
+	}
+
+	if nulls == 0 {
+		t.Error("NewUnique() did not generate nulls")
+	}
+}
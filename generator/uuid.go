@@ -0,0 +1,94 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"time"
+
+	"github.com/jackc/pgtype"
+)
+
+type uuidType struct {
+	pgtype.UUID
+	rand    *rand.Rand
+	version byte
+	// Inclusive range of Unix milliseconds for version 7.
+	minMillis, maxMillis int64
+}
+
+func (u *uuidType) NextValue() {
+	b := &u.UUID.Bytes
+	u.rand.Read(b[:])
+
+	if u.version == 7 {
+		ms := u.minMillis + u.rand.Int63n(u.maxMillis-u.minMillis+1)
+
+		var ts [8]byte
+		binary.BigEndian.PutUint64(ts[:], uint64(ms))
+		copy(b[:6], ts[2:])
+	}
+	b[6] = b[6]&0x0f | u.version<<4
+
+	// RFC 4122 variant.
+	b[8] = b[8]&0x3f | 0x80
+
+	u.UUID.Status = pgtype.Present
+}
+
+// NewUUIDv4 returns a generator of random (version 4) UUID values.
+func NewUUIDv4(seed int64, nullProbabilty float32) Value {
+	return &value{
+		Value: &uuidType{
+			rand: rand.New(
+				rand.NewSource(seed),
+			),
+			version: 4,
+		},
+		nulls: newNull(seed, nullProbabilty),
+	}
+}
+
+const maxUUIDv7Millis = 1<<48 - 1
+
+// MaxUUIDv7Time is the highest timestamp that fits into a version 7 UUID.
+var MaxUUIDv7Time = time.Unix(maxUUIDv7Millis/1000, maxUUIDv7Millis%1000*int64(time.Millisecond)).UTC()
+
+// NewUUIDv7 returns a generator of time-ordered (version 7) UUID values.
+//
+// The embedded timestamps are uniformly distributed between min and max,
+// inclusive, with millisecond precision. The remaining bits are random.
+// Min must not be before the Unix epoch, max not after MaxUUIDv7Time
+// and min must not be after max.
+func NewUUIDv7(seed int64, nullProbabilty float32, min, max time.Time) Value {
+	return &value{
+		Value: &uuidType{
+			rand: rand.New(
+				rand.NewSource(seed),
+			),
+			version:   7,
+			minMillis: floorDiv(unixMicro(min), 1000),
+			maxMillis: floorDiv(unixMicro(max), 1000),
+		},
+		nulls: newNull(seed, nullProbabilty),
+	}
+}
@@ -0,0 +1,91 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package generator
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_uuidType(t *testing.T) {
+	tests := []struct {
+		name string
+		v    Value
+		want []string
+	}{
+		{
+			"null",
+			NewUUIDv4(1, 100),
+			[]string{""},
+		},
+		{
+			"v4",
+			NewUUIDv4(3, 0),
+			[]string{"85fbe72b-6064-4890-84a5-31f967898df5", "319ee029-92fd-4840-a1fa-5052434bf6ee"},
+		},
+		{
+			"v7",
+			NewUUIDv7(3, 0, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC)),
+			[]string{"0176be7f-72be-7890-84a5-31f967898df5"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, len(tt.want))
+
+			for i := range got {
+				buf, err := tt.v.EncodeText(nil, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got[i] = string(buf)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("valueGenerator.EncodeText() =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_uuidType_v7Time(t *testing.T) {
+	min := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	max := min.Add(time.Second)
+	v := NewUUIDv7(1, 0, min, max)
+
+	for i := 0; i < 100; i++ {
+		v.NextValue()
+		b := v.Get().([16]byte)
+
+		var ms int64
+		for _, c := range b[:6] {
+			ms = ms<<8 | int64(c)
+		}
+
+		if ts := time.Unix(0, ms*int64(time.Millisecond)); ts.Before(min) || ts.After(max) {
+			t.Fatalf("uuid timestamp %v not between %v and %v", ts, min, max)
+		}
+		if b[6]>>4 != 7 {
+			t.Fatalf("uuid version %d, want 7", b[6]>>4)
+		}
+	}
+}
@@ -0,0 +1,528 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"os/signal"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/muhlemmer/pg_testdata/parse"
+	"gopkg.in/yaml.v2"
+)
+
+const columnsQuery = `select format('%I.%I', c.table_schema, c.table_name)::regclass::text,
+	c.column_name::text,
+	c.is_nullable::text = 'NO',
+	c.is_generated::text = 'ALWAYS' or c.is_identity::text = 'YES' or coalesce(c.column_default::text like 'nextval(%', false),
+	c.udt_schema::text,
+	c.udt_name::text,
+	c.character_maximum_length::int4,
+	c.numeric_precision::int4,
+	c.numeric_scale::int4
+from information_schema.columns c
+join information_schema.tables t using (table_schema, table_name)
+where c.table_schema = $1 and t.table_type = 'BASE TABLE'
+order by 1, c.ordinal_position;`
+
+const constraintsQuery = `select c.conrelid::regclass::text,
+	a.attname::text,
+	c.contype::text,
+	pg_get_constraintdef(c.oid),
+	case when c.contype = 'f' then c.confrelid::regclass::text else '' end,
+	coalesce(fa.attname::text, '')
+from pg_constraint c
+join pg_namespace n on n.oid = c.connamespace
+join pg_attribute a on a.attrelid = c.conrelid and a.attnum = c.conkey[1]
+left join pg_attribute fa on fa.attrelid = c.confrelid and fa.attnum = c.confkey[1]
+where n.nspname = $1 and c.contype in ('p', 'u', 'f', 'c') and array_length(c.conkey, 1) = 1
+order by c.conname;`
+
+const enumsQuery = `select n.nspname::text, t.typname::text, e.enumlabel::text
+from pg_enum e
+join pg_type t on t.oid = e.enumtypid
+join pg_namespace n on n.oid = t.typnamespace
+order by e.enumtypid, e.enumsortorder;`
+
+// queryRows executes query and calls scan for each row.
+func queryRows(ctx context.Context, pool *pgxpool.Pool, query string, scan func(rows pgx.Rows) error, args ...interface{}) {
+	runWithCtxTimeout(ctx, 30*time.Second, func(ctx context.Context) {
+		rows, err := pool.Query(ctx, query, args...)
+		if err != nil {
+			panic(fmt.Errorf("main.queryRows: %w", err))
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			if err = scan(rows); err != nil {
+				panic(fmt.Errorf("main.queryRows: %w", err))
+			}
+		}
+		if err = rows.Err(); err != nil {
+			panic(fmt.Errorf("main.queryRows: %w", err))
+		}
+	})
+}
+
+// catalogColumn as read from information_schema.columns.
+type catalogColumn struct {
+	table, name        string
+	notNull, generated bool
+	udtSchema, udtName string
+	length             *int32
+	precision, scale   *int32
+}
+
+// columnConstraints holds the single column constraints of a column.
+type columnConstraints struct {
+	unique bool
+	ref    *parse.Reference
+	checks []string
+}
+
+// catalog of a schema, used to generate a config.
+type catalog struct {
+	columns     []catalogColumn
+	constraints map[[2]string]*columnConstraints // by table and column name.
+	enums       map[string][]interface{}         // labels by qualified type name.
+}
+
+func readCatalog(ctx context.Context, pool *pgxpool.Pool, schema string) *catalog {
+	cat := &catalog{
+		constraints: make(map[[2]string]*columnConstraints),
+		enums:       make(map[string][]interface{}),
+	}
+
+	queryRows(ctx, pool, columnsQuery, func(rows pgx.Rows) error {
+		var col catalogColumn
+		if err := rows.Scan(&col.table, &col.name, &col.notNull, &col.generated, &col.udtSchema, &col.udtName, &col.length, &col.precision, &col.scale); err != nil {
+			return err
+		}
+
+		cat.columns = append(cat.columns, col)
+		return nil
+	}, schema)
+
+	queryRows(ctx, pool, constraintsQuery, func(rows pgx.Rows) error {
+		var table, column, tp, def, refTable, refColumn string
+		if err := rows.Scan(&table, &column, &tp, &def, &refTable, &refColumn); err != nil {
+			return err
+		}
+
+		key := [2]string{table, column}
+		cons, ok := cat.constraints[key]
+		if !ok {
+			cons = new(columnConstraints)
+			cat.constraints[key] = cons
+		}
+
+		switch tp {
+		case "p", "u":
+			cons.unique = true
+		case "f":
+			cons.ref = &parse.Reference{Table: refTable, Column: refColumn}
+		case "c":
+			cons.checks = append(cons.checks, def)
+		}
+
+		return nil
+	}, schema)
+
+	queryRows(ctx, pool, enumsQuery, func(rows pgx.Rows) error {
+		var schema, name, label string
+		if err := rows.Scan(&schema, &name, &label); err != nil {
+			return err
+		}
+
+		key := schema + "." + name
+		cat.enums[key] = append(cat.enums[key], label)
+
+		return nil
+	})
+
+	return cat
+}
+
+// checkPattern matches a comparison of a column with a number,
+// as formatted by pg_get_constraintdef. For example "(price > (0)::numeric)".
+var checkPattern = regexp.MustCompile(`\(+"?(\w+)"?\s*(>=|>|<=|<)\s*\(*'?(-?\d+(?:\.\d+)?)'?\)*(?:::[\w ]+)?\)`)
+
+// checkRange returns the inclusive bounds of column found in check constraints,
+// or nil for absent bounds. Exclusive bounds are moved by step,
+// or to the next float64 if step is 0.
+func checkRange(column string, checks []string, step float64) (min, max *float64) {
+	next := func(v, direction float64) float64 {
+		if step == 0 {
+			return math.Nextafter(v, math.Inf(int(direction)))
+		}
+		return v + direction*step
+	}
+
+	for _, def := range checks {
+		for _, m := range checkPattern.FindAllStringSubmatch(def, -1) {
+			if m[1] != column {
+				continue
+			}
+			v, err := strconv.ParseFloat(m[3], 64)
+			if err != nil {
+				continue
+			}
+
+			switch m[2] {
+			case ">":
+				v = next(v, 1)
+				fallthrough
+			case ">=":
+				if min == nil || v > *min {
+					min = &v
+				}
+			case "<":
+				v = next(v, -1)
+				fallthrough
+			case "<=":
+				if max == nil || v < *max {
+					max = &v
+				}
+			}
+		}
+	}
+
+	return min, max
+}
+
+// defaultRange is the span of generated numbers,
+// when not limited by check constraints.
+const defaultRange = 1000
+
+// numberRange returns the range of numbers for column,
+// from check constraints when available. Otherwise min defaults to 0
+// and max to min + 1000. Bounds are clamped between lower and upper.
+func numberRange(column string, checks []string, step, lower, upper float64) (min, max float64) {
+	min, max = 0, defaultRange
+
+	cmin, cmax := checkRange(column, checks, step)
+	if cmin != nil {
+		min, max = *cmin, *cmin+defaultRange
+	}
+	if cmax != nil {
+		if max = *cmax; cmin == nil && min > max {
+			min = max - defaultRange
+		}
+	}
+
+	min = math.Max(lower, math.Min(min, upper))
+	max = math.Max(lower, math.Min(max, upper))
+
+	return min, max
+}
+
+// maxExactInt is the largest integer exactly represented by a float64.
+const maxExactInt = 1 << 53
+
+// intBounds are the lower and upper bounds for integer types,
+// limited to integers exactly represented by a float64.
+var intBounds = map[string][2]float64{
+	"int2": {math.MinInt16, math.MaxInt16},
+	"int4": {math.MinInt32, math.MaxInt32},
+	"int8": {-maxExactInt, maxExactInt},
+}
+
+// columnConfig returns a column config with a generator for col,
+// or nil if the type of col is not supported.
+// Columns with a single column foreign key become a reference.
+func (cat *catalog) columnConfig(col catalogColumn, seed int64) *parse.Column {
+	c := &parse.Column{
+		Name:      col.name,
+		Seed:      seed,
+		Generator: make(map[parse.ArgName]interface{}),
+	}
+	if !col.notNull {
+		c.NullProbability = 10
+	}
+
+	cons := cat.constraints[[2]string{col.table, col.name}]
+	if cons == nil {
+		cons = new(columnConstraints)
+	}
+
+	if cons.ref != nil {
+		c.Type = parse.ReferenceType
+		c.Generator[parse.TableArg] = cons.ref.Table
+		c.Generator[parse.ColumnArg] = cons.ref.Column
+		c.Unique = cons.unique
+
+		return c
+	}
+
+	switch col.udtName {
+	case "bool":
+		c.Type = parse.BoolType
+		c.Generator[parse.ProbabilityArg] = 50
+
+	case "int2", "int4", "int8":
+		c.Type = parse.TypeName(col.udtName)
+
+		if cons.unique {
+			c.Generator[parse.ModeArg] = parse.SequenceMode
+			return c
+		}
+
+		bounds := intBounds[col.udtName]
+		min, max := numberRange(col.name, cons.checks, 1, bounds[0], bounds[1])
+		c.Generator[parse.MinArg] = int64(min)
+		c.Generator[parse.MaxArg] = int64(max)
+
+	case "numeric":
+		c.Type = parse.NumericType
+
+		if col.precision == nil {
+			c.Generator[parse.ScaleArg] = 2
+			c.Generator[parse.MinArg], c.Generator[parse.MaxArg] = numberRange(col.name, cons.checks, 0.01, -math.MaxFloat64, math.MaxFloat64)
+			break
+		}
+
+		var scale int32
+		if col.scale != nil {
+			scale = *col.scale
+		}
+		c.Generator[parse.PrecisionArg] = int(*col.precision)
+		c.Generator[parse.ScaleArg] = int(scale)
+
+		// Without checks, min and max default to the bounds of the type.
+		min, max := checkRange(col.name, cons.checks, math.Pow10(-int(scale)))
+		if min != nil {
+			c.Generator[parse.MinArg] = *min
+		}
+		if max != nil {
+			c.Generator[parse.MaxArg] = *max
+		}
+
+	case "float4", "float8":
+		c.Type = parse.TypeName(col.udtName)
+		c.Generator[parse.MinArg], c.Generator[parse.MaxArg] = numberRange(col.name, cons.checks, 0, -math.MaxFloat32, math.MaxFloat32)
+
+	case "text", "varchar":
+		c.Type = parse.TypeName(col.udtName)
+		if col.length != nil {
+			c.Generator[parse.LengthArg] = int(*col.length)
+		} else {
+			c.Generator[parse.MaxLengthArg] = 32
+		}
+
+	case "bpchar":
+		c.Type = parse.CharType
+		if col.length != nil {
+			c.Generator[parse.LengthArg] = int(*col.length)
+		}
+
+	case "date", "timestamp", "timestamptz":
+		c.Type = parse.TypeName(col.udtName)
+		c.Generator[parse.MinArg] = "-365d"
+		c.Generator[parse.MaxArg] = parse.Now
+
+	case "time":
+		c.Type = parse.TimeType
+		c.Generator[parse.GranularityArg] = "second"
+
+	case "interval":
+		c.Type = parse.IntervalType
+		c.Generator[parse.MinArg] = "0s"
+		c.Generator[parse.MaxArg] = "24h"
+
+	case "uuid":
+		c.Type = parse.UUIDType
+		c.Generator[parse.VersionArg] = 4
+
+	default:
+		labels, ok := cat.enums[col.udtSchema+"."+col.udtName]
+		if !ok {
+			return nil
+		}
+
+		c.Type = parse.EnumType
+		c.Generator[parse.ChoiceArg] = labels
+	}
+
+	c.Unique = cons.unique
+	return c
+}
+
+// distinctValues returns the amount of distinct values of a bool or enum column,
+// or 0 for any other type.
+func distinctValues(c *parse.Column) int {
+	switch c.Type {
+	case parse.BoolType:
+		return 2
+	case parse.EnumType:
+		labels, _ := c.Generator[parse.ChoiceArg].([]interface{})
+		return len(labels)
+	default:
+		return 0
+	}
+}
+
+// config returns a config for all tables in the catalog,
+// with amount rows for each table. Seeds are numbered by column, for each table.
+// Generated and identity columns are skipped,
+// as well as columns of unsupported types and tables without any columns left.
+// References to the own table or to a table outside the config are skipped too.
+// The amount of a table is limited to the distinct values of its unique bool and enum columns.
+func (cat *catalog) config(amount int) *parse.Config {
+	conf := new(parse.Config)
+
+	var (
+		table *parse.Table
+		seed  int64
+	)
+
+	for _, col := range cat.columns {
+		if table == nil || table.Name != col.table {
+			table = &parse.Table{
+				Name:   col.table,
+				Amount: amount,
+				MaxDuration: parse.TableDurations{
+					Table: time.Minute,
+					Exec:  time.Second,
+				},
+			}
+			conf.Tables = append(conf.Tables, table)
+			seed = 0
+		}
+		if col.generated {
+			continue
+		}
+
+		seed++
+
+		c := cat.columnConfig(col, seed)
+		if c == nil {
+			if col.notNull {
+				log.Printf("skipping not null column %q of table %q: unsupported type %q", col.name, col.table, col.udtName)
+			} else {
+				log.Printf("skipping column %q of table %q: unsupported type %q", col.name, col.table, col.udtName)
+			}
+			continue
+		}
+
+		if n := distinctValues(c); c.Unique && n > 0 && n < table.Amount {
+			log.Printf("limiting amount of table %q to %d: unique column %q has %d distinct values", col.table, n, col.name, n)
+			table.Amount = n
+		}
+
+		table.Columns = append(table.Columns, c)
+	}
+
+	// Skipping references may leave tables without columns,
+	// which are skipped in turn, until no more tables are skipped.
+	for {
+		names := make(map[string]bool, len(conf.Tables))
+		for _, table := range conf.Tables {
+			names[table.Name] = true
+		}
+
+		tables := conf.Tables[:0]
+		for _, table := range conf.Tables {
+			table.Columns = skipReferences(table, names)
+
+			if len(table.Columns) == 0 {
+				log.Printf("skipping table %q: no columns to generate", table.Name)
+				continue
+			}
+			tables = append(tables, table)
+		}
+
+		conf.Tables = tables
+		if len(tables) == len(names) {
+			return conf
+		}
+	}
+}
+
+// skipReferences returns the columns of table, without the reference columns
+// to table itself or to tables not in names.
+// The referenced values of those columns are not available when the table is loaded.
+func skipReferences(table *parse.Table, names map[string]bool) []*parse.Column {
+	columns := table.Columns[:0]
+
+	for _, c := range table.Columns {
+		ref, err := c.Reference()
+		if err != nil {
+			panic(fmt.Errorf("main.skipReferences: %w", err))
+		}
+
+		if ref != nil && (ref.Table == table.Name || !names[ref.Table]) {
+			if c.NullProbability == 0 {
+				log.Printf("skipping not null column %q of table %q: cannot reference table %q", c.Name, table.Name, ref.Table)
+			} else {
+				log.Printf("skipping column %q of table %q: cannot reference table %q", c.Name, table.Name, ref.Table)
+			}
+			continue
+		}
+
+		columns = append(columns, c)
+	}
+
+	return columns
+}
+
+// initCommand writes a starter config in yaml to w,
+// generated from the schema of a live database.
+func initCommand(args []string, w io.Writer) (exit int) {
+	defer fatal(&exit)
+
+	fs := flag.NewFlagSet("init", flag.ContinueOnError)
+	dsn := fs.String("dsn", "", "Data Source Name, aka connection string, of the database")
+	schema := fs.String("schema", "public", "Schema of the tables to include")
+	amount := fs.Int("amount", 1000, "Amount of rows to generate for each table")
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
+	defer cancel()
+
+	pool := connectDB(ctx, *dsn, 0)
+	defer pool.Close()
+
+	conf := readCatalog(ctx, pool, *schema).config(*amount)
+	conf.DSN = *dsn
+
+	out, err := yaml.Marshal(conf)
+	if err != nil {
+		panic(fmt.Errorf("main.initCommand: %w", err))
+	}
+	if _, err = w.Write(out); err != nil {
+		panic(fmt.Errorf("main.initCommand: %w", err))
+	}
+
+	return 0
+}
@@ -0,0 +1,348 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/muhlemmer/pg_testdata/parse"
+	"gopkg.in/yaml.v2"
+)
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+func Test_checkRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		checks  []string
+		step    float64
+		wantMin *float64
+		wantMax *float64
+	}{
+		{
+			"no checks",
+			nil,
+			1,
+			nil,
+			nil,
+		},
+		{
+			"other column",
+			[]string{"CHECK ((other > 0))"},
+			1,
+			nil,
+			nil,
+		},
+		{
+			"exclusive int",
+			[]string{"CHECK (((col > 0) AND (col < 100)))"},
+			1,
+			floatPtr(1),
+			floatPtr(99),
+		},
+		{
+			"inclusive numeric",
+			[]string{"CHECK ((col >= (0.5)::numeric))", "CHECK ((col <= '-1.5'::numeric))"},
+			0.01,
+			floatPtr(0.5),
+			floatPtr(-1.5),
+		},
+		{
+			"tightest",
+			[]string{"CHECK ((col >= 0))", "CHECK ((col >= 10))"},
+			1,
+			floatPtr(10),
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotMin, gotMax := checkRange("col", tt.checks, tt.step)
+			if !reflect.DeepEqual(gotMin, tt.wantMin) {
+				t.Errorf("checkRange() gotMin = %v, want %v", gotMin, tt.wantMin)
+			}
+			if !reflect.DeepEqual(gotMax, tt.wantMax) {
+				t.Errorf("checkRange() gotMax = %v, want %v", gotMax, tt.wantMax)
+			}
+		})
+	}
+}
+
+func Test_numberRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		checks  []string
+		upper   float64
+		wantMin float64
+		wantMax float64
+	}{
+		{
+			"default",
+			nil,
+			1e6,
+			0,
+			1000,
+		},
+		{
+			"min",
+			[]string{"CHECK ((col >= 5000))"},
+			1e6,
+			5000,
+			6000,
+		},
+		{
+			"max",
+			[]string{"CHECK ((col <= '-10'::integer))"},
+			1e6,
+			-1010,
+			-10,
+		},
+		{
+			"clamped",
+			[]string{"CHECK ((col >= 32000))"},
+			32767,
+			32000,
+			32767,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotMin, gotMax := numberRange("col", tt.checks, 1, -tt.upper, tt.upper)
+			if gotMin != tt.wantMin || gotMax != tt.wantMax {
+				t.Errorf("numberRange() = %v, %v, want %v, %v", gotMin, gotMax, tt.wantMin, tt.wantMax)
+			}
+		})
+	}
+}
+
+func Test_initCommand(t *testing.T) {
+	if exit := initCommand([]string{"-foo"}, new(bytes.Buffer)); exit != 2 {
+		t.Errorf("initCommand() = %d, want 2", exit)
+	}
+
+	var buf bytes.Buffer
+	if exit := initCommand([]string{"-dsn", testDSN(), "-amount", "10"}, &buf); exit != 0 {
+		t.Fatalf("initCommand() = %d, want 0", exit)
+	}
+
+	filename := filepath.Join(t.TempDir(), "init.yml")
+	if err := os.WriteFile(filename, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	conf, err := parse.Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got *parse.Table
+	for _, table := range conf.Tables {
+		if table.Name == "init_tests" {
+			got = table
+		}
+	}
+	if got == nil {
+		t.Fatal("initCommand() table init_tests not found")
+	}
+
+	want := []*parse.Column{
+		{
+			Name:      "code",
+			Seed:      1,
+			Type:      parse.UUIDType,
+			Generator: map[parse.ArgName]interface{}{parse.VersionArg: 4},
+			Unique:    true,
+		},
+		{
+			Name:      "qty",
+			Seed:      2,
+			Type:      parse.Int4Type,
+			Generator: map[parse.ArgName]interface{}{parse.MinArg: 1, parse.MaxArg: 100},
+		},
+		{
+			Name:            "price",
+			Seed:            3,
+			NullProbability: 10,
+			Type:            parse.NumericType,
+			Generator:       map[parse.ArgName]interface{}{parse.PrecisionArg: 8, parse.ScaleArg: 2, parse.MinArg: 0},
+		},
+		{
+			Name:      "name",
+			Seed:      4,
+			Type:      parse.VarcharType,
+			Generator: map[parse.ArgName]interface{}{parse.LengthArg: 20},
+		},
+		{
+			Name:      "status",
+			Seed:      5,
+			Type:      parse.EnumType,
+			Generator: map[parse.ArgName]interface{}{parse.ChoiceArg: []interface{}{"active", "suspended", "deleted"}},
+		},
+		{
+			Name:            "parent_id",
+			Seed:            6,
+			NullProbability: 10,
+			Type:            parse.ReferenceType,
+			Generator:       map[parse.ArgName]interface{}{parse.TableArg: "reference_parents", parse.ColumnArg: "id"},
+		},
+	}
+
+	if !reflect.DeepEqual(got.Columns, want) {
+		t.Errorf("initCommand() columns =\n%v\nwant\n%v", got.Columns, want)
+	}
+	if got.Amount != 10 {
+		t.Errorf("initCommand() amount = %d, want 10", got.Amount)
+	}
+
+	for _, table := range conf.Tables {
+		if table.Name != "init_tree_tests" {
+			continue
+		}
+		for _, col := range table.Columns {
+			if col.Name == "parent_id" {
+				t.Errorf("initCommand() self reference %q not skipped", col.Name)
+			}
+		}
+		return
+	}
+	t.Error("initCommand() table init_tree_tests not found")
+}
+
+func Test_catalog_config(t *testing.T) {
+	ref := func(table string) *columnConstraints {
+		return &columnConstraints{ref: &parse.Reference{Table: table, Column: "id"}}
+	}
+
+	cat := &catalog{
+		columns: []catalogColumn{
+			{table: "parents", name: "id", notNull: true, udtName: "int4"},
+			{table: "children", name: "parent_id", udtName: "int4"},
+			{table: "children", name: "other_id", notNull: true, udtName: "int4"},
+			{table: "trees", name: "id", udtName: "int4"},
+			{table: "trees", name: "parent_id", udtName: "int4"},
+			{table: "leaves", name: "other_id", udtName: "int4"},
+			{table: "leaves_children", name: "leaf_id", udtName: "int4"},
+		},
+		constraints: map[[2]string]*columnConstraints{
+			{"children", "parent_id"}:      ref("parents"),
+			{"children", "other_id"}:       ref("other.parents"),
+			{"trees", "parent_id"}:         ref("trees"),
+			{"leaves", "other_id"}:         ref("other.parents"),
+			{"leaves_children", "leaf_id"}: ref("leaves"),
+		},
+	}
+
+	conf := cat.config(10)
+
+	got := make(map[string][]string)
+	for _, table := range conf.Tables {
+		for _, col := range table.Columns {
+			got[table.Name] = append(got[table.Name], col.Name)
+		}
+	}
+	want := map[string][]string{
+		"parents":  {"id"},
+		"children": {"parent_id"},
+		"trees":    {"id"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("catalog.config() columns = %v, want %v", got, want)
+	}
+
+	if _, err := conf.InsertOrder(nil); err != nil {
+		t.Errorf("catalog.config() InsertOrder: %v", err)
+	}
+}
+
+func Test_catalog_config_unique(t *testing.T) {
+	unique := &columnConstraints{unique: true}
+
+	cat := &catalog{
+		columns: []catalogColumn{
+			{table: "flags", name: "id", notNull: true, udtName: "int4"},
+			{table: "flags", name: "flag", notNull: true, udtName: "bool"},
+			{table: "moods", name: "mood", udtSchema: "public", udtName: "mood"},
+			{table: "users", name: "id", notNull: true, udtName: "int4"},
+		},
+		constraints: map[[2]string]*columnConstraints{
+			{"flags", "id"}:   unique,
+			{"flags", "flag"}: unique,
+			{"moods", "mood"}: unique,
+			{"users", "id"}:   unique,
+		},
+		enums: map[string][]interface{}{
+			"public.mood": {"sad", "ok", "happy"},
+		},
+	}
+
+	conf := cat.config(10)
+
+	got := make(map[string]int)
+	for _, table := range conf.Tables {
+		got[table.Name] = table.Amount
+	}
+	want := map[string]int{
+		"flags": 2,
+		"moods": 3,
+		"users": 10,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("catalog.config() amounts = %v, want %v", got, want)
+	}
+
+	for _, table := range conf.Tables {
+		if errs := table.Validate(); errs != nil {
+			t.Errorf("catalog.config() table %q: %v", table.Name, errs)
+		}
+	}
+}
+
+func Test_catalog_config_labels(t *testing.T) {
+	cat := &catalog{
+		columns: []catalogColumn{
+			{table: "answers", name: "answer", notNull: true, udtSchema: "public", udtName: "answer"},
+		},
+		enums: map[string][]interface{}{
+			"public.answer": {"yes", "no", "on", "off", "maybe"},
+		},
+	}
+
+	out, err := yaml.Marshal(cat.config(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf, err := parse.Parse(out)
+	if err != nil {
+		t.Fatalf("parse.Parse() error = %v\n%s", err, out)
+	}
+	for _, table := range conf.Tables {
+		if errs := table.Validate(); errs != nil {
+			t.Errorf("catalog.config() labels: %v\n%s", errs, out)
+		}
+	}
+}
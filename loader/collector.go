@@ -0,0 +1,224 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package loader
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/jackc/pgtype"
+	"github.com/muhlemmer/pg_testdata/generator"
+	"github.com/muhlemmer/pg_testdata/parse"
+)
+
+// collected rows of a table.
+type collected struct {
+	columns []string
+	rows    [][]interface{}
+	text    [][][]byte
+}
+
+// Collector is a Sink which keeps the rows of the loaded tables in memory.
+// It is safe for concurrent use, but the rows of concurrently loaded
+// chunks are collected in any order.
+// Constraints and on conflict clauses are not applied.
+type Collector struct {
+	mu     sync.Mutex
+	ci     *pgtype.ConnInfo
+	tables map[string]*collected
+}
+
+// NewCollector returns an empty Collector.
+func NewCollector() *Collector {
+	return &Collector{
+		ci:     pgtype.NewConnInfo(),
+		tables: make(map[string]*collected),
+	}
+}
+
+// Columns returns the column names of a loaded table.
+func (c *Collector) Columns(table string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if t, ok := c.tables[table]; ok {
+		return t.columns
+	}
+	return nil
+}
+
+// Rows returns the rows of a loaded table, with the values in column order.
+// Values are of the Go type returned by the Get method of their pgtype,
+// such as bool, int32, string or time.Time. Null values are nil.
+func (c *Collector) Rows(table string) [][]interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if t, ok := c.tables[table]; ok {
+		return t.rows
+	}
+	return nil
+}
+
+// ForeignKeys are not known to a Collector.
+// Tables are ordered by their reference columns only.
+func (c *Collector) ForeignKeys(ctx context.Context, tables []*parse.Table) (map[string][]string, error) {
+	return nil, nil
+}
+
+// Reset removes the collected rows of tables with a before strategy.
+func (c *Collector) Reset(ctx context.Context, tables []*parse.Table, reset bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, table := range tables {
+		before, err := table.BeforeLoad(reset)
+		if err != nil {
+			return fmt.Errorf("loader.Collector.Reset: %w", err)
+		}
+		if before != parse.BeforeNone {
+			delete(c.tables, table.Name)
+		}
+	}
+
+	return nil
+}
+
+// Referenced returns the non-null values of the referenced column,
+// in collected order. Only the text format is set.
+func (c *Collector) Referenced(ctx context.Context, ref *parse.Reference) ([]generator.Encoded, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, ok := c.tables[ref.Table]
+	if !ok {
+		return nil, nil
+	}
+
+	for i, name := range t.columns {
+		if name != ref.Column {
+			continue
+		}
+
+		var values []generator.Encoded
+		for _, row := range t.text {
+			if row[i] != nil {
+				values = append(values, generator.Encoded{Text: row[i]})
+			}
+		}
+		return values, nil
+	}
+
+	return nil, fmt.Errorf("loader.Collector.Referenced: column %s does not exist", ref)
+}
+
+// decoder returns a new pgtype value, which decodes the text format of tp.
+// Types without a corresponding pgtype are decoded as text.
+func (c *Collector) decoder(tp parse.TypeName) pgtype.Value {
+	name := string(tp)
+	if tp == parse.CharType {
+		name = "bpchar"
+	}
+
+	dt, ok := c.ci.DataTypeForName(name)
+	if !ok {
+		dt, _ = c.ci.DataTypeForName("text")
+	}
+	return pgtype.NewValue(dt.Value)
+}
+
+// Load collects the rows of table. Commit is ignored.
+// Values are generated in text format, like a dry run,
+// and decoded with the pgtype of their column.
+func (c *Collector) Load(ctx context.Context, table *parse.Table, commit int) (int64, error) {
+	columns, args, err := table.CopyFrom()
+	if err != nil {
+		return 0, fmt.Errorf("loader.Collector.Load: %w", err)
+	}
+
+	decoders := make([]pgtype.Value, len(table.Columns))
+	for i, col := range table.Columns {
+		decoders[i] = c.decoder(col.Type)
+	}
+
+	rows := make([][]interface{}, table.Amount)
+	text := make([][][]byte, table.Amount)
+
+	for i := range rows {
+		if err = ctx.Err(); err != nil {
+			return 0, fmt.Errorf("loader.Collector.Load: %w", err)
+		}
+
+		rows[i] = make([]interface{}, len(args))
+		text[i] = make([][]byte, len(args))
+
+		for j, arg := range args {
+			if text[i][j], err = arg.(pgtype.TextEncoder).EncodeText(c.ci, nil); err != nil {
+				return 0, fmt.Errorf("loader.Collector.Load: %w for column %q of table %q", err, columns[j], table.Name)
+			}
+			if err = decoders[j].(pgtype.TextDecoder).DecodeText(c.ci, text[i][j]); err != nil {
+				return 0, fmt.Errorf("loader.Collector.Load: %w for column %q of table %q", err, columns[j], table.Name)
+			}
+			rows[i][j] = decoders[j].Get()
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, ok := c.tables[table.Name]
+	if !ok {
+		t = &collected{columns: columns}
+		c.tables[table.Name] = t
+	}
+	t.rows = append(t.rows, rows...)
+	t.text = append(t.text, text...)
+
+	return int64(table.Amount), nil
+}
+
+// Tx calls f with the Collector. The rows collected by f are discarded
+// if it returns an error.
+func (c *Collector) Tx(ctx context.Context, f func(tx Sink) error) error {
+	c.mu.Lock()
+	saved := make(map[string]collected, len(c.tables))
+	for name, t := range c.tables {
+		saved[name] = *t
+	}
+	c.mu.Unlock()
+
+	err := f(c)
+	if err == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tables = make(map[string]*collected, len(saved))
+	for name, t := range saved {
+		t := t
+		c.tables[name] = &t
+	}
+
+	return err
+}
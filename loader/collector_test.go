@@ -0,0 +1,208 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package loader
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/muhlemmer/pg_testdata/parse"
+)
+
+// TestCollector_regression checks that a Collector
+// collects the same data as loaded into the database.
+func TestCollector_regression(t *testing.T) {
+	conf, err := parse.Load("../testdata/regression_test.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewCollector()
+	if _, err = New(conf, c).Load(testCtx); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open("../testdata/regression.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var want []struct {
+		Name string
+		Data []interface{}
+	}
+	if err = json.NewDecoder(file).Decode(&want); err != nil {
+		t.Fatal(err)
+	}
+
+	columns := c.Columns("regression_tests")
+	rows := c.Rows("regression_tests")
+
+	for i, col := range want {
+		if columns[i] != col.Name {
+			t.Fatalf("Collector column %d = %s, want %s", i, columns[i], col.Name)
+		}
+		if len(rows) != len(col.Data) {
+			t.Fatalf("Collector rows = %d, want %d", len(rows), len(col.Data))
+		}
+
+		for j, row := range rows {
+			if row[i] != col.Data[j] {
+				t.Fatalf("Collector row %d column %s = %v, want %v", j, col.Name, row[i], col.Data[j])
+			}
+		}
+	}
+}
+
+func TestCollector(t *testing.T) {
+	parents := &parse.Table{
+		Name:   "parents",
+		Amount: 3,
+		Columns: []*parse.Column{
+			{
+				Name: "id",
+				Type: parse.Int4Type,
+				Generator: map[parse.ArgName]interface{}{
+					parse.ModeArg:  parse.SequenceMode,
+					parse.StartArg: 1,
+				},
+			},
+			{
+				Name:            "name",
+				Type:            parse.TextType,
+				NullProbability: 100,
+				Generator: map[parse.ArgName]interface{}{
+					parse.MaxLengthArg: 10,
+				},
+			},
+		},
+	}
+	reference := func(table, column string) *parse.Table {
+		return &parse.Table{
+			Name:   "children",
+			Amount: 4,
+			Columns: []*parse.Column{
+				{
+					Name: "parent_id",
+					Type: parse.ReferenceType,
+					Generator: map[parse.ArgName]interface{}{
+						parse.TableArg:  table,
+						parse.ColumnArg: column,
+					},
+				},
+			},
+		}
+	}
+
+	c := NewCollector()
+
+	l := New(&parse.Config{Tables: []*parse.Table{reference("parents", "id"), parents}}, c)
+	results, err := l.Load(testCtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Rows != 3 || results[1].Rows != 4 {
+		t.Fatalf("Collector results = %v", results)
+	}
+
+	want := [][]interface{}{{int32(1), nil}, {int32(2), nil}, {int32(3), nil}}
+	if got := c.Rows("parents"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Collector.Rows() = %v, want %v", got, want)
+	}
+	for _, row := range c.Rows("children") {
+		if id, _ := row[0].(string); id < "1" || id > "3" {
+			t.Errorf("Collector.Rows() parent_id = %v, want 1, 2 or 3", row[0])
+		}
+	}
+
+	t.Run("Missing column", func(t *testing.T) {
+		_, err := New(&parse.Config{Tables: []*parse.Table{reference("parents", "foo")}}, c).Load(testCtx)
+		if err == nil {
+			t.Error("Loader.Load() expected error")
+		}
+	})
+
+	t.Run("Empty reference", func(t *testing.T) {
+		_, err := New(&parse.Config{Tables: []*parse.Table{reference("others", "id")}}, c).Load(testCtx)
+		if err == nil {
+			t.Error("Loader.Load() expected error")
+		}
+	})
+
+	t.Run("Rollback", func(t *testing.T) {
+		failing := reference("parents", "id")
+		failing.Columns = append(failing.Columns, &parse.Column{Name: "foo", Type: "foo"})
+
+		conf := &parse.Config{
+			Transaction: parse.AllTransaction,
+			Tables:      []*parse.Table{parents, failing},
+		}
+		if _, err := New(conf, c).Load(testCtx); err == nil {
+			t.Fatal("Loader.Load() expected error")
+		}
+
+		if got := c.Rows("parents"); !reflect.DeepEqual(got, want) {
+			t.Errorf("Collector.Rows() after rollback = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		l := New(&parse.Config{Tables: []*parse.Table{parents}}, c)
+		l.Reset = true
+
+		if _, err := l.Load(testCtx); err != nil {
+			t.Fatal(err)
+		}
+		if got := c.Rows("parents"); !reflect.DeepEqual(got, want) {
+			t.Errorf("Collector.Rows() after reset = %v, want %v", got, want)
+		}
+		if got := c.Rows("children"); len(got) != 4 {
+			t.Errorf("Collector.Rows() of children = %d, want 4", len(got))
+		}
+	})
+}
+
+// TestCollector_shared checks that a config with references
+// can be loaded concurrently.
+func TestCollector_shared(t *testing.T) {
+	conf, err := parse.Load("../testdata/reference_test.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if _, err := New(conf, NewCollector()).Load(testCtx); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+}
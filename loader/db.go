@@ -0,0 +1,474 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package loader
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgtype"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/muhlemmer/pg_testdata/generator"
+	"github.com/muhlemmer/pg_testdata/parse"
+)
+
+// DB is a database connection or transaction, used for loading tables.
+// It is implemented by *pgxpool.Pool, *pgxpool.Conn, *pgx.Conn and pgx.Tx.
+type DB interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
+// withTimeout returns a context with a timeout of d.
+// A d of 0 or less means no timeout.
+func withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+func runWithCtxTimeout(ctx context.Context, d time.Duration, f func(context.Context)) {
+	ctx, cancel := withTimeout(ctx, d)
+	defer cancel()
+
+	f(ctx)
+}
+
+func acquireConn(ctx context.Context, pool *pgxpool.Pool) (conn *pgxpool.Conn) {
+	runWithCtxTimeout(ctx, 5*time.Second, func(ctx context.Context) {
+		var err error
+
+		conn, err = pool.Acquire(ctx)
+		if err != nil {
+			panic(fmt.Errorf("loader.acquire: %w", err))
+		}
+	})
+
+	return conn
+}
+
+// inTx runs f in a transaction, which is committed when f returns.
+// The transaction is rolled back if f panics,
+// also when ctx is canceled.
+func inTx(ctx context.Context, conn DB, f func(tx pgx.Tx)) {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		panic(fmt.Errorf("loader.inTx: %w", err))
+	}
+	defer runWithCtxTimeout(context.Background(), 5*time.Second, func(ctx context.Context) {
+		tx.Rollback(ctx)
+	})
+
+	f(tx)
+
+	if err = tx.Commit(ctx); err != nil {
+		panic(fmt.Errorf("loader.inTx: %w", err))
+	}
+}
+
+// commitRows calls load for amount rows.
+// If commit is larger than 0, load is called with a transaction
+// for each commit rows, which is committed before the next rows are loaded.
+func commitRows(ctx context.Context, conn DB, amount, commit int, load func(conn DB, rows int)) {
+	if commit < 1 {
+		load(conn, amount)
+		return
+	}
+
+	for i := 0; i < amount; i += commit {
+		rows := amount - i
+		if rows > commit {
+			rows = commit
+		}
+
+		inTx(ctx, conn, func(tx pgx.Tx) { load(tx, rows) })
+	}
+}
+
+// insertStatements returns insert statements for a batch of rows.
+// The statements are prepared and cached by the connection on first use.
+type insertStatements struct {
+	table *parse.Table
+	batch int
+	args  []interface{}
+	stmts map[int]string
+}
+
+func newInsertStatements(table *parse.Table) *insertStatements {
+	batch, err := table.Batch()
+	if err != nil {
+		panic(err)
+	}
+
+	stmt, args, err := table.BatchInsertQuery(batch)
+	if err != nil {
+		panic(err)
+	}
+
+	return &insertStatements{
+		table: table,
+		batch: batch,
+		args:  args,
+		stmts: map[int]string{batch: stmt},
+	}
+}
+
+// get the statement with args for rows.
+// The args of the batch are repeated for each row,
+// so the same generators are used for any amount of rows.
+func (s *insertStatements) get(rows int) (string, []interface{}) {
+	stmt, ok := s.stmts[rows]
+	if !ok {
+		var err error
+		if stmt, _, err = s.table.BatchInsertQuery(rows); err != nil {
+			panic(err)
+		}
+		s.stmts[rows] = stmt
+	}
+
+	return stmt, s.args[:rows*len(s.table.Columns)]
+}
+
+// execInserts inserts the rows of table, in transactions of commit rows.
+// Each statement inserts up to the batch size of rows.
+// It returns the amount of rows affected by the statements,
+// which excludes rows skipped by an on conflict clause.
+func execInserts(ctx context.Context, conn DB, table *parse.Table, commit int) (affected int64) {
+	ctx, cancel := withTimeout(ctx, table.MaxDuration.Table)
+	defer cancel()
+
+	stmts := newInsertStatements(table)
+
+	commitRows(ctx, conn, table.Amount, commit, func(conn DB, amount int) {
+		for i := 0; i < amount; i += stmts.batch {
+			rows := amount - i
+			if rows > stmts.batch {
+				rows = stmts.batch
+			}
+			stmt, args := stmts.get(rows)
+
+			runWithCtxTimeout(ctx, table.MaxDuration.Exec, func(ctx context.Context) {
+				tag, err := conn.Exec(ctx, stmt, args...)
+				if err != nil {
+					panic(fmt.Errorf("loader.execInsert: %w for table %q", err, table.Name))
+				}
+				affected += tag.RowsAffected()
+			})
+		}
+	})
+
+	return affected
+}
+
+// copySource is a pgx.CopyFromSource, which returns the same
+// value generators for amount rows.
+type copySource struct {
+	args   []interface{}
+	n      int
+	amount int
+}
+
+func (s *copySource) Next() bool {
+	s.n++
+	return s.n <= s.amount
+}
+
+func (s *copySource) Values() ([]interface{}, error) {
+	return s.args, nil
+}
+
+func (s *copySource) Err() error {
+	return nil
+}
+
+// copyReference encodes the values of a reference generator
+// in the binary format of the column they are copied into,
+// by decoding their text format with the type of the column.
+// Types without a corresponding pgtype, such as enums,
+// use the text format as binary format.
+type copyReference struct {
+	value pgtype.TextEncoder
+	oid   uint32
+}
+
+func (r copyReference) EncodeBinary(ci *pgtype.ConnInfo, buf []byte) ([]byte, error) {
+	text, err := r.value.EncodeText(ci, []byte{})
+	if err != nil || text == nil {
+		return nil, err
+	}
+
+	dt, ok := ci.DataTypeForOID(r.oid)
+	if !ok {
+		return append(buf, text...), nil
+	}
+
+	v := pgtype.NewValue(dt.Value)
+	if err = v.(pgtype.TextDecoder).DecodeText(ci, text); err != nil {
+		return nil, err
+	}
+	return v.(pgtype.BinaryEncoder).EncodeBinary(ci, buf)
+}
+
+// copyReferences replaces the args of reference columns by a copyReference,
+// with the type of the column in the table.
+// Referenced and referencing column may have different types,
+// while COPY requires the binary format of the referencing column.
+func copyReferences(ctx context.Context, conn DB, table *parse.Table, columns []string, args []interface{}) {
+	var refs bool
+	for _, col := range table.Columns {
+		refs = refs || col.Type == parse.ReferenceType
+	}
+	if !refs {
+		return
+	}
+
+	idents := make([]string, len(columns))
+	for i, name := range columns {
+		idents[i] = pgx.Identifier{name}.Sanitize()
+	}
+	query := fmt.Sprintf("select %s from %s limit 0;", strings.Join(idents, ", "), pgx.Identifier(strings.Split(table.Name, ".")).Sanitize())
+
+	runWithCtxTimeout(ctx, table.MaxDuration.Exec, func(ctx context.Context) {
+		rows, err := conn.Query(ctx, query)
+		if err != nil {
+			panic(fmt.Errorf("loader.copyReferences: %w for table %q", err, table.Name))
+		}
+		fields := rows.FieldDescriptions()
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			panic(fmt.Errorf("loader.copyReferences: %w for table %q", err, table.Name))
+		}
+
+		for i, col := range table.Columns {
+			if col.Type == parse.ReferenceType {
+				args[i] = copyReference{value: args[i].(pgtype.TextEncoder), oid: fields[i].DataTypeOID}
+			}
+		}
+	})
+}
+
+// execCopy loads the rows of table with COPY FROM STDIN,
+// in transactions of commit rows.
+// The table name may be qualified with a schema.
+// It returns the amount of copied rows.
+func execCopy(ctx context.Context, conn DB, table *parse.Table, commit int) (copied int64) {
+	ctx, cancel := withTimeout(ctx, table.MaxDuration.Table)
+	defer cancel()
+
+	columns, args, err := table.CopyFrom()
+	if err != nil {
+		panic(err)
+	}
+	copyReferences(ctx, conn, table, columns, args)
+
+	commitRows(ctx, conn, table.Amount, commit, func(conn DB, rows int) {
+		src := &copySource{args: args, amount: rows}
+		n, err := conn.CopyFrom(ctx, pgx.Identifier(strings.Split(table.Name, ".")), columns, src)
+		if err != nil {
+			panic(fmt.Errorf("loader.execCopy: %w for table %q", err, table.Name))
+		}
+		copied += n
+	})
+
+	return copied
+}
+
+// loadTable loads the rows of table, with its configured method,
+// in transactions of commit rows. If commit is 0,
+// each statement is committed on its own.
+// A pool connection is acquired for the duration of the load.
+// It returns the amount of loaded rows.
+func loadTable(ctx context.Context, conn DB, table *parse.Table, commit int) int64 {
+	method, err := table.LoadMethod()
+	if err != nil {
+		panic(err)
+	}
+
+	if pool, ok := conn.(*pgxpool.Pool); ok {
+		c := acquireConn(ctx, pool)
+		defer c.Release()
+		conn = c
+	}
+
+	switch method {
+	case parse.CopyMethod:
+		return execCopy(ctx, conn, table, commit)
+	default:
+		return execInserts(ctx, conn, table, commit)
+	}
+}
+
+// resetTables resets the tables before loading, in a single transaction.
+// Tables must be in insert order. Reset applies to tables without a before strategy.
+// Each statement may take as long as the longest MaxDuration of the tables,
+// without limit if any of the tables has none.
+func resetTables(ctx context.Context, conn DB, tables []*parse.Table, reset bool) {
+	queries, err := parse.ResetQueries(tables, reset)
+	if err != nil {
+		panic(err)
+	}
+	if len(queries) == 0 {
+		return
+	}
+
+	var timeout time.Duration
+	for _, table := range tables {
+		if table.MaxDuration.Table <= 0 {
+			timeout = 0
+			break
+		}
+		if table.MaxDuration.Table > timeout {
+			timeout = table.MaxDuration.Table
+		}
+	}
+
+	inTx(ctx, conn, func(tx pgx.Tx) {
+		for _, query := range queries {
+			runWithCtxTimeout(ctx, timeout, func(ctx context.Context) {
+				if _, err := tx.Exec(ctx, query.SQL, query.Args...); err != nil {
+					panic(fmt.Errorf("loader.resetTables: %w", err))
+				}
+			})
+		}
+	})
+}
+
+// queryReferenced returns all non-null values of the referenced column,
+// in text format. The table name may be qualified with a schema.
+func queryReferenced(ctx context.Context, conn DB, ref *parse.Reference) (values []generator.Encoded) {
+	column := pgx.Identifier{ref.Column}.Sanitize()
+	query := fmt.Sprintf("select %s::text from %s where %s is not null;", column, pgx.Identifier(strings.Split(ref.Table, ".")).Sanitize(), column)
+
+	rows, err := conn.Query(ctx, query, pgx.QueryResultFormats{pgx.TextFormatCode})
+	if err != nil {
+		panic(fmt.Errorf("loader.queryReferenced: %w for %s", err, ref))
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		raw := rows.RawValues()
+
+		values = append(values, generator.Encoded{
+			Text: append([]byte(nil), raw[0]...),
+		})
+	}
+	if err = rows.Err(); err != nil {
+		panic(fmt.Errorf("loader.queryReferenced: %w for %s", err, ref))
+	}
+
+	return values
+}
+
+const foreignKeysQuery = `select t.name, r.name
+from unnest($1::text[]) as t(name)
+join pg_constraint c on c.conrelid = to_regclass(t.name)
+join unnest($1::text[]) as r(name) on c.confrelid = to_regclass(r.name)
+where c.contype = 'f';`
+
+// foreignKeys returns the names of the tables referenced by foreign keys,
+// for each of the tables. Only names of the tables are returned.
+func foreignKeys(ctx context.Context, conn DB, tables []*parse.Table) map[string][]string {
+	names := make([]string, len(tables))
+	for i, table := range tables {
+		names[i] = table.Name
+	}
+
+	deps := make(map[string][]string)
+
+	runWithCtxTimeout(ctx, 5*time.Second, func(ctx context.Context) {
+		rows, err := conn.Query(ctx, foreignKeysQuery, names)
+		if err != nil {
+			panic(fmt.Errorf("loader.foreignKeys: %w", err))
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var table, ref string
+			if err = rows.Scan(&table, &ref); err != nil {
+				panic(fmt.Errorf("loader.foreignKeys: %w", err))
+			}
+			deps[table] = append(deps[table], ref)
+		}
+		if err = rows.Err(); err != nil {
+			panic(fmt.Errorf("loader.foreignKeys: %w", err))
+		}
+	})
+
+	return deps
+}
+
+// dbSink is a Sink which loads tables into a database.
+type dbSink struct {
+	conn DB
+}
+
+// NewDBSink returns a Sink which loads tables into the database of conn.
+// Only a *pgxpool.Pool is safe for concurrent use,
+// as required for a Loader with Parallel larger than 1.
+// Loading into a pgx.Tx uses savepoints for the transactions of the config.
+func NewDBSink(conn DB) Sink {
+	return &dbSink{conn: conn}
+}
+
+// ForeignKeys reads the foreign keys between the tables from the catalog.
+func (s *dbSink) ForeignKeys(ctx context.Context, tables []*parse.Table) (fks map[string][]string, err error) {
+	defer recoverError("loader.dbSink.ForeignKeys", &err)
+	return foreignKeys(ctx, s.conn, tables), nil
+}
+
+// Reset executes the reset queries of the tables in a single transaction.
+func (s *dbSink) Reset(ctx context.Context, tables []*parse.Table, reset bool) (err error) {
+	defer recoverError("loader.dbSink.Reset", &err)
+	resetTables(ctx, s.conn, tables, reset)
+	return nil
+}
+
+// Referenced queries the non-null values of the referenced column,
+// in text format.
+func (s *dbSink) Referenced(ctx context.Context, ref *parse.Reference) (values []generator.Encoded, err error) {
+	defer recoverError("loader.dbSink.Referenced", &err)
+	return queryReferenced(ctx, s.conn, ref), nil
+}
+
+// Load the table with its configured method.
+func (s *dbSink) Load(ctx context.Context, table *parse.Table, commit int) (rows int64, err error) {
+	defer recoverError("loader.dbSink.Load", &err)
+	return loadTable(ctx, s.conn, table, commit), nil
+}
+
+// Tx calls f with a Sink in a database transaction.
+func (s *dbSink) Tx(ctx context.Context, f func(tx Sink) error) (err error) {
+	defer recoverError("loader.dbSink.Tx", &err)
+
+	inTx(ctx, s.conn, func(tx pgx.Tx) {
+		if err := f(&dbSink{conn: tx}); err != nil {
+			panic(err)
+		}
+	})
+	return nil
+}
@@ -0,0 +1,711 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package loader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgtype"
+	"github.com/jackc/pgx/v4"
+	"github.com/muhlemmer/pg_testdata/generator"
+	"github.com/muhlemmer/pg_testdata/parse"
+)
+
+func Test_acquireConn(t *testing.T) {
+	ectx, cancel := context.WithCancel(testCtx)
+	cancel()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		wantErr bool
+	}{
+		{
+			"Context error",
+			ectx,
+			true,
+		},
+		{
+			"Succes",
+			testCtx,
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := func() (err error) {
+				defer func() { err, _ = recover().(error) }()
+				conn := acquireConn(tt.ctx, testDB)
+				defer conn.Release()
+
+				return conn.Ping(testCtx)
+			}()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("acquireConn() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
+
+func Test_withTimeout(t *testing.T) {
+	tests := []struct {
+		name         string
+		d            time.Duration
+		wantDeadline bool
+	}{
+		{"No limit", 0, false},
+		{"Negative", -time.Second, false},
+		{"Timeout", time.Second, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := withTimeout(context.Background(), tt.d)
+			defer cancel()
+
+			if _, ok := ctx.Deadline(); ok != tt.wantDeadline {
+				t.Errorf("withTimeout() deadline = %v, want %v", ok, tt.wantDeadline)
+			}
+			if err := ctx.Err(); err != nil {
+				t.Errorf("withTimeout() error = %v", err)
+			}
+		})
+	}
+}
+
+func Test_insertStatements(t *testing.T) {
+	table := func(batchSize int, typ parse.TypeName) *parse.Table {
+		return &parse.Table{
+			Name:      "unit_tests",
+			Amount:    5,
+			BatchSize: batchSize,
+			Columns: []*parse.Column{
+				{
+					Name: "bool_col",
+					Type: typ,
+					Generator: map[parse.ArgName]interface{}{
+						parse.ProbabilityArg: 100,
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		table    *parse.Table
+		rows     int
+		wantStmt string
+		wantArgs int
+		wantErr  bool
+	}{
+		{
+			"Batch error",
+			table(-1, parse.BoolType),
+			1,
+			"",
+			0,
+			true,
+		},
+		{
+			"InsertQuery error",
+			table(1, "does-not-exist"),
+			1,
+			"",
+			0,
+			true,
+		},
+		{
+			"Batch",
+			table(2, parse.BoolType),
+			2,
+			"insert into unit_tests (bool_col) values ($1), ($2);",
+			2,
+			false,
+		},
+		{
+			"Rest",
+			table(2, parse.BoolType),
+			1,
+			"insert into unit_tests (bool_col) values ($1);",
+			1,
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := func() (err error) {
+				defer func() { err, _ = recover().(error) }()
+
+				stmts := newInsertStatements(tt.table)
+				gotStmt, gotArgs := stmts.get(tt.rows)
+				if gotStmt != tt.wantStmt {
+					t.Errorf("insertStatements.get() stmt = %s, want %s", gotStmt, tt.wantStmt)
+				}
+				if len(gotArgs) != tt.wantArgs {
+					t.Errorf("insertStatements.get() args len = %d, want %d", len(gotArgs), tt.wantArgs)
+				}
+
+				return
+			}()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("newInsertStatements() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
+
+func countRows(t *testing.T, table string) (n int) {
+	runWithCtxTimeout(testCtx, time.Second, func(ctx context.Context) {
+		if err := testDB.QueryRow(ctx, fmt.Sprintf("select count(*) from %s;", table)).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+	})
+	return n
+}
+
+func Test_commitRows(t *testing.T) {
+	const insert = "insert into unit_tests (bool_col) values (true);"
+
+	tests := []struct {
+		name     string
+		amount   int
+		commit   int
+		failAt   int
+		wantRows int
+		wantErr  bool
+	}{
+		{"Without transactions", 5, 0, 0, 5, false},
+		{"Transactions", 5, 2, 0, 5, false},
+		{"Rollback", 5, 2, 4, 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := countRows(t, "unit_tests")
+
+			var loaded int
+			err := func() (err error) {
+				defer func() { err, _ = recover().(error) }()
+
+				commitRows(testCtx, testDB, tt.amount, tt.commit, func(conn DB, rows int) {
+					if _, isTx := conn.(pgx.Tx); isTx != (tt.commit > 0) {
+						t.Errorf("commitRows() transaction = %v, want %v", isTx, tt.commit > 0)
+					}
+
+					for i := 0; i < rows; i++ {
+						if loaded++; loaded == tt.failAt {
+							panic(errors.New("load failed"))
+						}
+						if _, err := conn.Exec(testCtx, insert); err != nil {
+							panic(err)
+						}
+					}
+				})
+				return
+			}()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("commitRows() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := countRows(t, "unit_tests") - before; got != tt.wantRows {
+				t.Errorf("commitRows() rows = %d, want %d", got, tt.wantRows)
+			}
+		})
+	}
+}
+
+func Test_execInserts(t *testing.T) {
+	tests := []struct {
+		name    string
+		table   *parse.Table
+		wantErr bool
+	}{
+		{
+			"Exec error",
+			&parse.Table{
+				Name:   "error_tests",
+				Amount: 5,
+				MaxDuration: parse.TableDurations{
+					Table: 10 * time.Second,
+					Exec:  1 * time.Second,
+				},
+				Columns: []*parse.Column{
+					{
+						Name: "bool_col",
+						Type: parse.BoolType,
+						Generator: map[parse.ArgName]interface{}{
+							parse.ProbabilityArg: 100,
+						},
+					},
+				},
+			},
+			true,
+		},
+		{
+			"Succes",
+			&parse.Table{
+				Name:   "unit_tests",
+				Amount: 5,
+				MaxDuration: parse.TableDurations{
+					Table: 10 * time.Second,
+					Exec:  1 * time.Second,
+				},
+				Columns: []*parse.Column{
+					{
+						Name: "bool_col",
+						Type: parse.BoolType,
+						Generator: map[parse.ArgName]interface{}{
+							parse.ProbabilityArg: 100,
+						},
+					},
+				},
+			},
+			false,
+		},
+		{
+			"No durations",
+			&parse.Table{
+				Name:   "unit_tests",
+				Amount: 5,
+				Columns: []*parse.Column{
+					{
+						Name: "bool_col",
+						Type: parse.BoolType,
+						Generator: map[parse.ArgName]interface{}{
+							parse.ProbabilityArg: 100,
+						},
+					},
+				},
+			},
+			false,
+		},
+		{
+			"Batch error",
+			&parse.Table{
+				Name:      "unit_tests",
+				Amount:    5,
+				BatchSize: -1,
+				MaxDuration: parse.TableDurations{
+					Table: 10 * time.Second,
+					Exec:  1 * time.Second,
+				},
+				Columns: []*parse.Column{
+					{
+						Name: "bool_col",
+						Type: parse.BoolType,
+						Generator: map[parse.ArgName]interface{}{
+							parse.ProbabilityArg: 100,
+						},
+					},
+				},
+			},
+			true,
+		},
+		{
+			"Batch",
+			&parse.Table{
+				Name:      "unit_tests",
+				Amount:    5,
+				BatchSize: 2,
+				MaxDuration: parse.TableDurations{
+					Table: 10 * time.Second,
+					Exec:  1 * time.Second,
+				},
+				Columns: []*parse.Column{
+					{
+						Name: "bool_col",
+						Type: parse.BoolType,
+						Generator: map[parse.ArgName]interface{}{
+							parse.ProbabilityArg: 100,
+						},
+					},
+				},
+			},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := func() (err error) {
+				defer func() { err, _ = recover().(error) }()
+				execInserts(testCtx, testDB, tt.table, 0)
+
+				return
+			}()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("execInserts() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+
+	}
+}
+
+func Test_execInserts_onConflict(t *testing.T) {
+	table := func(action parse.ConflictAction) *parse.Table {
+		return &parse.Table{
+			Name:   "reference_parents",
+			Amount: 3,
+			MaxDuration: parse.TableDurations{
+				Table: 10 * time.Second,
+				Exec:  1 * time.Second,
+			},
+			OnConflict: &parse.OnConflict{
+				Action:  action,
+				Columns: []string{"id"},
+				Update:  []string{"id"},
+			},
+			Columns: []*parse.Column{
+				{
+					Name: "id",
+					Type: parse.Int4Type,
+					Generator: map[parse.ArgName]interface{}{
+						parse.ChoiceArg: []interface{}{7001},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		table  *parse.Table
+		commit int
+		want   int64
+	}{
+		{"Nothing", table(parse.ConflictNothing), 0, 1},
+		{"Nothing again", table(parse.ConflictNothing), 2, 0},
+		{"Update", table(parse.ConflictUpdate), 0, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := execInserts(testCtx, testDB, tt.table, tt.commit); got != tt.want {
+				t.Errorf("execInserts() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_resetTables(t *testing.T) {
+	const insert = "insert into init_tests (code, qty, name, status) values (md5(random()::text)::uuid, 1, 'reset', 'active') returning id;"
+
+	tests := []struct {
+		name    string
+		tables  []*parse.Table
+		reset   bool
+		wantErr bool
+	}{
+		{
+			"Unsupported",
+			[]*parse.Table{{Name: "init_tests", Before: "drop"}},
+			false,
+			true,
+		},
+		{
+			"Exec error",
+			[]*parse.Table{{Name: "does_not_exist", Before: parse.BeforeDelete}},
+			false,
+			true,
+		},
+		{
+			"Delete",
+			[]*parse.Table{{Name: "init_tests", Before: parse.BeforeDelete, RestartIdentity: true}},
+			false,
+			false,
+		},
+		{
+			"Reset",
+			[]*parse.Table{{Name: "init_tests", RestartIdentity: true}},
+			true,
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var id int
+			for i := 0; i < 2; i++ {
+				runWithCtxTimeout(testCtx, time.Second, func(ctx context.Context) {
+					if err := testDB.QueryRow(ctx, insert).Scan(&id); err != nil {
+						t.Fatal(err)
+					}
+				})
+			}
+
+			err := func() (err error) {
+				defer func() { err, _ = recover().(error) }()
+				resetTables(testCtx, testDB, tt.tables, tt.reset)
+
+				return
+			}()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("resetTables() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				execQuerySlice(testCtx, []string{"truncate init_tests restart identity;"})
+				return
+			}
+
+			if n := countRows(t, "init_tests"); n != 0 {
+				t.Errorf("resetTables() left %d rows", n)
+			}
+			runWithCtxTimeout(testCtx, time.Second, func(ctx context.Context) {
+				if err := testDB.QueryRow(ctx, insert).Scan(&id); err != nil {
+					t.Fatal(err)
+				}
+			})
+			if id != 1 {
+				t.Errorf("resetTables() next id = %d, want 1", id)
+			}
+			execQuerySlice(testCtx, []string{"delete from init_tests;"})
+		})
+	}
+}
+
+func Test_loadReferences(t *testing.T) {
+	execQuerySlice(testCtx, []string{"insert into reference_parents (id) values (1001), (1002), (1003);"})
+
+	referencing := func(table, column string) *parse.Table {
+		return &parse.Table{
+			Name:   "reference_children",
+			Amount: 5,
+			MaxDuration: parse.TableDurations{
+				Table: 10 * time.Second,
+				Exec:  1 * time.Second,
+			},
+			Columns: []*parse.Column{
+				{
+					Name: "parent_id",
+					Type: parse.ReferenceType,
+					Generator: map[parse.ArgName]interface{}{
+						parse.TableArg:  table,
+						parse.ColumnArg: column,
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		table   *parse.Table
+		wantErr bool
+	}{
+		{
+			"Missing argument",
+			&parse.Table{
+				Name: "reference_children",
+				Columns: []*parse.Column{
+					{
+						Name: "parent_id",
+						Type: parse.ReferenceType,
+					},
+				},
+			},
+			true,
+		},
+		{
+			"Query error",
+			referencing("does_not_exist", "id"),
+			true,
+		},
+		{
+			"Empty table",
+			referencing("error_tests", "text_col"),
+			true,
+		},
+		{
+			"Success",
+			referencing("reference_parents", "id"),
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := func() (err error) {
+				defer func() { err, _ = recover().(error) }()
+				loadReferences(testCtx, NewDBSink(testDB), tt.table)
+				execInserts(testCtx, testDB, tt.table, 0)
+
+				return
+			}()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("loadReferences() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
+
+func Test_queryReferenced(t *testing.T) {
+	execQuerySlice(testCtx, []string{`insert into "Reference_Keys" ("order") values (2), (1);`})
+
+	got := queryReferenced(testCtx, testDB, &parse.Reference{Table: "Reference_Keys", Column: "order"})
+	if len(got) != 2 {
+		t.Errorf("queryReferenced() = %v, want 2 values", got)
+	}
+}
+
+func Test_foreignKeys(t *testing.T) {
+	tables := []*parse.Table{
+		{Name: "reference_children"},
+		{Name: "reference_parents"},
+		{Name: "unit_tests"},
+		{Name: "does_not_exist"},
+	}
+
+	want := map[string][]string{
+		"reference_children": {"reference_parents"},
+	}
+
+	if got := foreignKeys(testCtx, testDB, tables); !reflect.DeepEqual(got, want) {
+		t.Errorf("foreignKeys() = %v, want %v", got, want)
+	}
+}
+
+func Test_copyReference_EncodeBinary(t *testing.T) {
+	ci := pgtype.NewConnInfo()
+	values := []generator.Encoded{{Text: []byte("1")}}
+
+	tests := []struct {
+		name    string
+		nulls   float32
+		oid     uint32
+		want    []byte
+		wantErr bool
+	}{
+		{"int8", 0, pgtype.Int8OID, []byte{0, 0, 0, 0, 0, 0, 0, 1}, false},
+		{"text", 0, pgtype.TextOID, []byte("1"), false},
+		{"unknown type", 0, 1, []byte("1"), false},
+		{"null", 100, pgtype.Int8OID, nil, false},
+		{"decode error", 0, pgtype.DateOID, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := copyReference{
+				value: generator.NewReference(1, tt.nulls, values, 0),
+				oid:   tt.oid,
+			}
+
+			got, err := r.EncodeBinary(ci, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("copyReference.EncodeBinary() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("copyReference.EncodeBinary() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_loadTable(t *testing.T) {
+	table := func(name string, method parse.Method) *parse.Table {
+		return &parse.Table{
+			Name:   name,
+			Amount: 5,
+			MaxDuration: parse.TableDurations{
+				Table: 10 * time.Second,
+				Exec:  1 * time.Second,
+			},
+			Method: method,
+			Columns: []*parse.Column{
+				{
+					Name: "bool_col",
+					Type: parse.BoolType,
+					Generator: map[parse.ArgName]interface{}{
+						parse.ProbabilityArg: 100,
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		table   *parse.Table
+		commit  int
+		wantErr bool
+	}{
+		{
+			"Unsupported method",
+			table("unit_tests", "foo"),
+			0,
+			true,
+		},
+		{
+			"Copy error",
+			table("error_tests", parse.CopyMethod),
+			0,
+			true,
+		},
+		{
+			"Copy",
+			table("unit_tests", parse.CopyMethod),
+			0,
+			false,
+		},
+		{
+			"Copy transactions",
+			table("unit_tests", parse.CopyMethod),
+			2,
+			false,
+		},
+		{
+			"Insert",
+			table("unit_tests", parse.InsertMethod),
+			0,
+			false,
+		},
+		{
+			"Insert transactions",
+			table("unit_tests", parse.InsertMethod),
+			2,
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := func() (err error) {
+				defer func() { err, _ = recover().(error) }()
+				loadTable(testCtx, testDB, tt.table, tt.commit)
+
+				return
+			}()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("loadTable() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
@@ -0,0 +1,310 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+package loader
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/muhlemmer/pg_testdata/generator"
+	"github.com/muhlemmer/pg_testdata/parse"
+)
+
+// Output formats of a DryRun.
+const (
+	SQLFormat  = "sql"  // Insert statements with literal values.
+	CopyFormat = "copy" // COPY FROM STDIN statements with text format data, as used by psql.
+)
+
+// literal returns the SQL literal of a text encoded value,
+// which is null for a nil value.
+func literal(value []byte) string {
+	if value == nil {
+		return "null"
+	}
+	return "'" + strings.ReplaceAll(string(value), "'", "''") + "'"
+}
+
+var copyEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+// copyValue returns a text encoded value, escaped for the text format of COPY.
+func copyValue(value []byte) string {
+	if value == nil {
+		return `\N`
+	}
+	return copyEscaper.Replace(string(value))
+}
+
+// dryRunWriter serializes the writes of a DryRun and the Sink of its Tx.
+type dryRunWriter struct {
+	mu sync.Mutex
+	w  *bufio.Writer
+}
+
+// DryRun is a Sink which writes the statements loading the tables to a writer,
+// with the values which would be sent to the database.
+// Foreign keys are not known to a DryRun, so tables are ordered by their
+// reference columns only, of which the referenced values are kept in memory.
+// It is safe for concurrent use, but loads are written one at a time,
+// in any order. The output is flushed after each call.
+type DryRun struct {
+	format string
+	tx     bool // Within Tx, where no transaction blocks are written.
+
+	out  *dryRunWriter
+	refs *referenceStore
+}
+
+// DryRunFormat reports if format is an output format of a DryRun.
+func DryRunFormat(format string) bool {
+	return format == SQLFormat || format == CopyFormat
+}
+
+// NewDryRun returns a DryRun writing to w in format, which defaults to SQLFormat.
+// An error is returned if the format is not supported.
+func NewDryRun(w io.Writer, format string) (*DryRun, error) {
+	if format == "" {
+		format = SQLFormat
+	}
+	if !DryRunFormat(format) {
+		return nil, fmt.Errorf("loader.NewDryRun: unsupported format %q", format)
+	}
+
+	return &DryRun{
+		format: format,
+		out:    &dryRunWriter{w: bufio.NewWriter(w)},
+		refs:   newReferenceStore(),
+	}, nil
+}
+
+func (d *DryRun) printf(format string, a ...interface{}) {
+	fmt.Fprintf(d.out.w, format, a...)
+}
+
+// inTx writes f in a transaction block, if tx is true.
+func (d *DryRun) inTx(tx bool, f func()) {
+	if !tx {
+		f()
+		return
+	}
+
+	d.printf("begin;\n")
+	f()
+	d.printf("commit;\n")
+}
+
+// ForeignKeys are not known to a DryRun.
+// Tables are ordered by their reference columns only,
+// of which the referenced columns are kept.
+func (d *DryRun) ForeignKeys(ctx context.Context, tables []*parse.Table) (map[string][]string, error) {
+	if err := d.refs.keep(tables); err != nil {
+		return nil, fmt.Errorf("loader.DryRun.ForeignKeys: %w", err)
+	}
+	return nil, nil
+}
+
+// Reset writes the reset queries of the tables in a transaction block.
+// The kept values of tables with a before strategy are removed.
+func (d *DryRun) Reset(ctx context.Context, tables []*parse.Table, reset bool) error {
+	queries, err := parse.ResetQueries(tables, reset)
+	if err != nil {
+		return fmt.Errorf("loader.DryRun.Reset: %w", err)
+	}
+
+	for _, table := range tables {
+		before, err := table.BeforeLoad(reset)
+		if err != nil {
+			return fmt.Errorf("loader.DryRun.Reset: %w", err)
+		}
+		if before != parse.BeforeNone {
+			d.refs.reset(table.Name)
+		}
+	}
+
+	d.out.mu.Lock()
+	defer d.out.mu.Unlock()
+
+	d.inTx(!d.tx && len(queries) > 0, func() {
+		for _, query := range queries {
+			d.printf("%s\n", query)
+		}
+	})
+
+	if err = d.out.w.Flush(); err != nil {
+		return fmt.Errorf("loader.DryRun.Reset: %w", err)
+	}
+	return nil
+}
+
+// Referenced returns the non-null values of the referenced column,
+// in written order.
+func (d *DryRun) Referenced(ctx context.Context, ref *parse.Reference) ([]generator.Encoded, error) {
+	return d.refs.values(ref), nil
+}
+
+// Load writes the rows of table with its configured method,
+// in transaction blocks of commit rows. If commit is 0,
+// no transaction blocks are written.
+// With CopyFormat, all rows are written as COPY data,
+// which does not support an on conflict clause.
+func (d *DryRun) Load(ctx context.Context, table *parse.Table, commit int) (rows int64, err error) {
+	defer recoverError("loader.DryRun.Load", &err)
+
+	method, err := table.LoadMethod()
+	if err != nil {
+		panic(err)
+	}
+	if d.format == CopyFormat && table.OnConflict != nil {
+		panic(fmt.Errorf("on_conflict of table %q not supported with format %q", table.Name, CopyFormat))
+	}
+
+	columns, args, err := table.CopyFrom()
+	if err != nil {
+		panic(err)
+	}
+
+	batch := 1
+	if method == parse.InsertMethod {
+		if batch, err = table.Batch(); err != nil {
+			panic(err)
+		}
+	}
+
+	tx := commit > 0 && !d.tx
+	if commit <= 0 {
+		commit = table.Amount
+	}
+
+	refs := d.refs.rows(table)
+	enc := newRowEncoder(len(args))
+
+	d.out.mu.Lock()
+	defer d.out.mu.Unlock()
+
+	for i := 0; i < table.Amount; i += commit {
+		n := table.Amount - i
+		if n > commit {
+			n = commit
+		}
+
+		d.inTx(tx, func() {
+			if d.format == CopyFormat {
+				d.copyRows(ctx, table, columns, args, enc, refs, n)
+			} else {
+				d.insertRows(ctx, table, args, enc, refs, n, batch)
+			}
+		})
+	}
+
+	if err = d.out.w.Flush(); err != nil {
+		panic(err)
+	}
+	refs.done()
+
+	return int64(table.Amount), nil
+}
+
+// encode the next row of args, which is added to refs.
+func (d *DryRun) encode(ctx context.Context, args []interface{}, enc *rowEncoder, refs *referencedRows) [][]byte {
+	if err := ctx.Err(); err != nil {
+		panic(err)
+	}
+
+	row, err := enc.encode(args)
+	if err != nil {
+		panic(err)
+	}
+	refs.add(row)
+
+	return row
+}
+
+// insertRows writes insert statements for rows, with up to batch rows per statement.
+func (d *DryRun) insertRows(ctx context.Context, table *parse.Table, args []interface{}, enc *rowEncoder, refs *referencedRows, rows, batch int) {
+	for i := 0; i < rows; i += batch {
+		n := rows - i
+		if n > batch {
+			n = batch
+		}
+
+		literals := make([][]string, n)
+		for r := range literals {
+			literals[r] = make([]string, len(args))
+			for c, v := range d.encode(ctx, args, enc, refs) {
+				literals[r][c] = literal(v)
+			}
+		}
+
+		stmt, err := table.LiteralInsertQuery(literals)
+		if err != nil {
+			panic(err)
+		}
+		d.printf("%s\n", stmt)
+	}
+}
+
+// copyRows writes a COPY FROM STDIN statement with rows of data.
+func (d *DryRun) copyRows(ctx context.Context, table *parse.Table, columns []string, args []interface{}, enc *rowEncoder, refs *referencedRows, rows int) {
+	d.printf("copy %s (%s) from stdin;\n", table.Name, strings.Join(columns, ", "))
+
+	for i := 0; i < rows; i++ {
+		for c, v := range d.encode(ctx, args, enc, refs) {
+			if c > 0 {
+				d.out.w.WriteByte('\t')
+			}
+			d.out.w.WriteString(copyValue(v))
+		}
+		d.out.w.WriteByte('\n')
+	}
+
+	d.printf("\\.\n")
+}
+
+// Tx calls f with a DryRun in a transaction block, which is committed
+// if f returns nil and rolled back otherwise. Within the block,
+// Reset and Load do not write transaction blocks of their own.
+func (d *DryRun) Tx(ctx context.Context, f func(tx Sink) error) error {
+	d.out.mu.Lock()
+	d.printf("begin;\n")
+	d.out.mu.Unlock()
+
+	tx := *d
+	tx.tx = true
+	err := f(&tx)
+
+	d.out.mu.Lock()
+	defer d.out.mu.Unlock()
+
+	if err != nil {
+		d.printf("rollback;\n")
+		d.out.w.Flush()
+		return err
+	}
+
+	d.printf("commit;\n")
+	if err = d.out.w.Flush(); err != nil {
+		return fmt.Errorf("loader.DryRun.Tx: %w", err)
+	}
+	return nil
+}
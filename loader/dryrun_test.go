@@ -0,0 +1,188 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+package loader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/muhlemmer/pg_testdata/parse"
+)
+
+func Test_literal(t *testing.T) {
+	tests := []struct {
+		name  string
+		value []byte
+		want  string
+	}{
+		{"Null", nil, "null"},
+		{"Empty", []byte{}, "''"},
+		{"Quotes", []byte(`it's a \ test`), `'it''s a \ test'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := literal(tt.value); got != tt.want {
+				t.Errorf("literal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_copyValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value []byte
+		want  string
+	}{
+		{"Null", nil, `\N`},
+		{"Empty", []byte{}, ""},
+		{"Escapes", []byte("a\\b\nc\rd\te"), `a\\b\nc\rd\te`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := copyValue(tt.value); got != tt.want {
+				t.Errorf("copyValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewDryRun(t *testing.T) {
+	if _, err := NewDryRun(new(bytes.Buffer), "foo"); err == nil {
+		t.Error("NewDryRun() expected error")
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	parents := &parse.Table{
+		Name:   "parents",
+		Amount: 2,
+		Before: parse.BeforeTruncate,
+		Columns: []*parse.Column{
+			{
+				Name: "id",
+				Type: parse.Int4Type,
+				Generator: map[parse.ArgName]interface{}{
+					parse.ModeArg:  parse.SequenceMode,
+					parse.StartArg: 1,
+				},
+			},
+		},
+	}
+	children := &parse.Table{
+		Name:   "children",
+		Amount: 2,
+		Method: parse.CopyMethod,
+		Columns: []*parse.Column{
+			{
+				Name: "parent_id",
+				Type: parse.ReferenceType,
+				Generator: map[parse.ArgName]interface{}{
+					parse.TableArg:  "parents",
+					parse.ColumnArg: "id",
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		conf    *parse.Config
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{
+			"SQL",
+			&parse.Config{Tables: []*parse.Table{children, parents}},
+			"",
+			"begin;\ntruncate table \"parents\";\ncommit;\n" +
+				"insert into parents (id) values ('1');\ninsert into parents (id) values ('2');\n" +
+				"insert into children (parent_id) values ('1');\ninsert into children (parent_id) values ('1');\n",
+			false,
+		},
+		{
+			"Copy in transaction",
+			&parse.Config{Transaction: parse.AllTransaction, Tables: []*parse.Table{children, parents}},
+			CopyFormat,
+			"begin;\ntruncate table \"parents\";\n" +
+				"copy parents (id) from stdin;\n1\n2\n\\.\n" +
+				"copy children (parent_id) from stdin;\n1\n1\n\\.\n" +
+				"commit;\n",
+			false,
+		},
+		{
+			"Rollback",
+			&parse.Config{Transaction: parse.AllTransaction, Tables: []*parse.Table{
+				{Name: "parents", Amount: 1, Columns: []*parse.Column{{Name: "id", Type: parse.BoolType}}},
+			}},
+			"",
+			"begin;\nrollback;\n",
+			true,
+		},
+		{
+			"Copy on conflict",
+			&parse.Config{Tables: []*parse.Table{
+				{Name: "parents", Amount: 1, OnConflict: &parse.OnConflict{Action: parse.ConflictNothing}, Columns: parents.Columns},
+			}},
+			CopyFormat,
+			"",
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			d, err := NewDryRun(&buf, tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = New(tt.conf, d).Load(testCtx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DryRun error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("DryRun =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDryRun_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(testCtx)
+	cancel()
+
+	d, err := NewDryRun(new(bytes.Buffer), SQLFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	table := &parse.Table{Name: "parents", Amount: 1, Columns: []*parse.Column{{
+		Name:      "id",
+		Type:      parse.BoolType,
+		Generator: map[parse.ArgName]interface{}{parse.ProbabilityArg: 50},
+	}}}
+	if _, err = d.Load(ctx, table, 0); !errors.Is(err, context.Canceled) {
+		t.Errorf("DryRun.Load() error = %v, want %v", err, context.Canceled)
+	}
+}
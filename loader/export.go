@@ -0,0 +1,555 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package loader
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgtype"
+	"github.com/muhlemmer/pg_testdata/generator"
+	"github.com/muhlemmer/pg_testdata/parse"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// File formats of an Exporter, which writes a file for each table.
+const (
+	CSVFormat     = "csv"     // Comma separated values with a header, compatible with COPY ... CSV HEADER.
+	JSONLFormat   = "jsonl"   // A JSON object for each row.
+	ParquetFormat = "parquet" // Apache Parquet.
+)
+
+// rowEncoder encodes rows of value generators in text format,
+// reusing a buffer for each column.
+type rowEncoder struct {
+	ci     *pgtype.ConnInfo
+	bufs   [][]byte
+	values [][]byte
+}
+
+func newRowEncoder(columns int) *rowEncoder {
+	e := &rowEncoder{
+		ci:     pgtype.NewConnInfo(),
+		bufs:   make([][]byte, columns),
+		values: make([][]byte, columns),
+	}
+	for i := range e.bufs {
+		e.bufs[i] = make([]byte, 0, 64)
+	}
+
+	return e
+}
+
+// encode the next row of args. The returned values are nil for SQL null
+// and are only valid until the next call.
+func (e *rowEncoder) encode(args []interface{}) ([][]byte, error) {
+	for i, arg := range args {
+		buf, err := arg.(pgtype.TextEncoder).EncodeText(e.ci, e.bufs[i][:0])
+		if err != nil {
+			return nil, err
+		}
+		if buf != nil {
+			e.bufs[i] = buf
+		}
+		e.values[i] = buf
+	}
+
+	return e.values, nil
+}
+
+// rowWriter writes the rows of a table to a file.
+type rowWriter interface {
+	// row writes the text encoded values of a row, which are nil for SQL null.
+	row(values [][]byte) error
+	// close writes the remaining data. It does not close the underlying writer.
+	close() error
+}
+
+// rowWriters holds the constructor of a rowWriter for each file format.
+var rowWriters = map[string]func(w io.Writer, columns []*parse.Column) (rowWriter, error){
+	CSVFormat:     newCSVWriter,
+	JSONLFormat:   newJSONLWriter,
+	ParquetFormat: newParquetWriter,
+}
+
+// csvField returns a value quoted for CSV, if required.
+// Like COPY, null is an empty field and an empty string is quoted.
+func csvField(value []byte) string {
+	if value == nil {
+		return ""
+	}
+
+	s := string(value)
+	if s == "" || strings.ContainsAny(s, ",\"\r\n") || s == `\.` {
+		return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	}
+	return s
+}
+
+type csvWriter struct {
+	w      io.Writer
+	fields []string
+}
+
+func newCSVWriter(w io.Writer, columns []*parse.Column) (rowWriter, error) {
+	s := &csvWriter{
+		w:      w,
+		fields: make([]string, len(columns)),
+	}
+	for i, col := range columns {
+		s.fields[i] = csvField([]byte(col.Name))
+	}
+
+	return s, s.line()
+}
+
+func (s *csvWriter) line() error {
+	_, err := io.WriteString(s.w, strings.Join(s.fields, ",")+"\n")
+	return err
+}
+
+func (s *csvWriter) row(values [][]byte) error {
+	for i, v := range values {
+		s.fields[i] = csvField(v)
+	}
+	return s.line()
+}
+
+func (s *csvWriter) close() error { return nil }
+
+// jsonNumber reports if values of type tp are written as JSON numbers.
+// Numeric values are written as strings, to retain their precision.
+func jsonNumber(tp parse.TypeName) bool {
+	switch tp {
+	case parse.Int2Type, parse.Int4Type, parse.Int8Type, parse.Float4Type, parse.Float8Type:
+		return true
+	default:
+		return false
+	}
+}
+
+type jsonlWriter struct {
+	w       io.Writer
+	columns []*parse.Column
+	keys    [][]byte
+	buf     bytes.Buffer
+}
+
+func newJSONLWriter(w io.Writer, columns []*parse.Column) (rowWriter, error) {
+	s := &jsonlWriter{
+		w:       w,
+		columns: columns,
+		keys:    make([][]byte, len(columns)),
+	}
+	for i, col := range columns {
+		key, err := json.Marshal(col.Name)
+		if err != nil {
+			return nil, err
+		}
+		s.keys[i] = key
+	}
+
+	return s, nil
+}
+
+// row writes an object with the values in column order.
+func (s *jsonlWriter) row(values [][]byte) error {
+	s.buf.Reset()
+	s.buf.WriteByte('{')
+
+	for i, v := range values {
+		if i > 0 {
+			s.buf.WriteByte(',')
+		}
+		s.buf.Write(s.keys[i])
+		s.buf.WriteByte(':')
+
+		switch {
+		case v == nil:
+			s.buf.WriteString("null")
+		case s.columns[i].Type == parse.BoolType:
+			s.buf.WriteString(fmt.Sprint(string(v) == "t"))
+		case jsonNumber(s.columns[i].Type):
+			s.buf.Write(v)
+		default:
+			str, err := json.Marshal(string(v))
+			if err != nil {
+				return err
+			}
+			s.buf.Write(str)
+		}
+	}
+
+	s.buf.WriteString("}\n")
+	_, err := s.w.Write(s.buf.Bytes())
+	return err
+}
+
+func (s *jsonlWriter) close() error { return nil }
+
+// parquetMetadata returns the schema of a column.
+// Types without a corresponding Parquet type are written as UTF-8 strings.
+func parquetMetadata(col *parse.Column) string {
+	var tp string
+
+	switch col.Type {
+	case parse.BoolType:
+		tp = "type=BOOLEAN"
+	case parse.Int2Type:
+		tp = "type=INT32, convertedtype=INT_16"
+	case parse.Int4Type:
+		tp = "type=INT32"
+	case parse.Int8Type:
+		tp = "type=INT64"
+	case parse.Float4Type:
+		tp = "type=FLOAT"
+	case parse.Float8Type:
+		tp = "type=DOUBLE"
+	default:
+		tp = "type=BYTE_ARRAY, convertedtype=UTF8"
+	}
+
+	return fmt.Sprintf("name=%s, %s, repetitiontype=OPTIONAL", col.Name, tp)
+}
+
+type parquetWriter struct {
+	pw     *writer.CSVWriter
+	values []*string
+}
+
+func newParquetWriter(w io.Writer, columns []*parse.Column) (rowWriter, error) {
+	md := make([]string, len(columns))
+	for i, col := range columns {
+		md[i] = parquetMetadata(col)
+	}
+
+	pw, err := writer.NewCSVWriterFromWriter(md, w, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	return &parquetWriter{
+		pw:     pw,
+		values: make([]*string, len(columns)),
+	}, nil
+}
+
+func (s *parquetWriter) row(values [][]byte) error {
+	for i, v := range values {
+		s.values[i] = nil
+		if v != nil {
+			str := string(v)
+			s.values[i] = &str
+		}
+	}
+	return s.pw.WriteString(s.values)
+}
+
+func (s *parquetWriter) close() error {
+	return s.pw.WriteStop()
+}
+
+// referenceStore keeps the values of referenced columns in memory,
+// for Sinks which can not read them back. It is safe for concurrent use.
+type referenceStore struct {
+	mu   sync.Mutex
+	refs map[parse.Reference][]generator.Encoded
+}
+
+func newReferenceStore() *referenceStore {
+	return &referenceStore{refs: make(map[parse.Reference][]generator.Encoded)}
+}
+
+// keep the values of the columns referenced by the reference columns of tables.
+func (s *referenceStore) keep(tables []*parse.Table) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, table := range tables {
+		for _, col := range table.Columns {
+			ref, err := col.Reference()
+			if err != nil {
+				return fmt.Errorf("%w for table %q", err, table.Name)
+			}
+			if ref == nil {
+				continue
+			}
+			if _, ok := s.refs[*ref]; !ok {
+				s.refs[*ref] = nil
+			}
+		}
+	}
+
+	return nil
+}
+
+// reset removes the kept values of the columns of table.
+func (s *referenceStore) reset(table string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for ref := range s.refs {
+		if ref.Table == table {
+			s.refs[ref] = nil
+		}
+	}
+}
+
+// values returns a copy of the kept values of ref, in loaded order.
+func (s *referenceStore) values(ref *parse.Reference) []generator.Encoded {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]generator.Encoded(nil), s.refs[*ref]...)
+}
+
+// referencedRows collects the values of the kept columns of a table,
+// from the rows of a single load.
+type referencedRows struct {
+	store  *referenceStore
+	refs   map[int]parse.Reference
+	values map[int][]generator.Encoded
+}
+
+// rows returns the referencedRows of table, with the kept columns by index.
+func (s *referenceStore) rows(table *parse.Table) *referencedRows {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := &referencedRows{
+		store:  s,
+		refs:   make(map[int]parse.Reference),
+		values: make(map[int][]generator.Encoded),
+	}
+	for i, col := range table.Columns {
+		ref := parse.Reference{Table: table.Name, Column: col.Name}
+		if _, ok := s.refs[ref]; ok {
+			r.refs[i] = ref
+		}
+	}
+
+	return r
+}
+
+// add copies the non-null values of the kept columns of row.
+func (r *referencedRows) add(row [][]byte) {
+	for i := range r.refs {
+		if row[i] != nil {
+			r.values[i] = append(r.values[i], generator.Encoded{Text: append([]byte(nil), row[i]...)})
+		}
+	}
+}
+
+// done appends the collected values to the store.
+func (r *referencedRows) done() {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for i, ref := range r.refs {
+		r.store.refs[ref] = append(r.store.refs[ref], r.values[i]...)
+	}
+}
+
+// exportFile of a table. Its mutex serializes the loads of the table's chunks.
+type exportFile struct {
+	mu sync.Mutex
+	f  *os.File
+	w  rowWriter
+}
+
+// close writes the remaining data and closes the file.
+func (f *exportFile) close() error {
+	err := f.w.close()
+	if cerr := f.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Exporter is a Sink which writes the rows of each table to a file in a directory,
+// named after the table with the extension of the file format.
+// Files are created, or truncated, on the first load of a table
+// and must be completed by Close.
+// The values of columns referenced by reference columns are kept in memory.
+// It is safe for concurrent use, but the rows of concurrently loaded
+// chunks are written in any order.
+// Constraints and on conflict clauses are not applied.
+type Exporter struct {
+	dir    string
+	format string
+
+	mu    sync.Mutex
+	files map[string]*exportFile
+	refs  *referenceStore
+}
+
+// ExportFormat reports if format is a file format of an Exporter.
+func ExportFormat(format string) bool {
+	_, ok := rowWriters[format]
+	return ok
+}
+
+// NewExporter returns an Exporter writing files in format to dir.
+// An error is returned if the format is not supported.
+func NewExporter(dir, format string) (*Exporter, error) {
+	if !ExportFormat(format) {
+		return nil, fmt.Errorf("loader.NewExporter: unsupported format %q", format)
+	}
+
+	return &Exporter{
+		dir:    dir,
+		format: format,
+		files:  make(map[string]*exportFile),
+		refs:   newReferenceStore(),
+	}, nil
+}
+
+// ForeignKeys are not known to an Exporter.
+// Tables are ordered by their reference columns only,
+// of which the referenced columns are kept.
+func (e *Exporter) ForeignKeys(ctx context.Context, tables []*parse.Table) (map[string][]string, error) {
+	if err := e.refs.keep(tables); err != nil {
+		return nil, fmt.Errorf("loader.Exporter.ForeignKeys: %w", err)
+	}
+	return nil, nil
+}
+
+// Reset completes the files of tables with a before strategy,
+// so they are truncated on the next load.
+func (e *Exporter) Reset(ctx context.Context, tables []*parse.Table, reset bool) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, table := range tables {
+		before, err := table.BeforeLoad(reset)
+		if err != nil {
+			return fmt.Errorf("loader.Exporter.Reset: %w", err)
+		}
+		if before == parse.BeforeNone {
+			continue
+		}
+
+		if f, ok := e.files[table.Name]; ok {
+			delete(e.files, table.Name)
+			if err = f.close(); err != nil {
+				return fmt.Errorf("loader.Exporter.Reset: %w for table %q", err, table.Name)
+			}
+		}
+		e.refs.reset(table.Name)
+	}
+
+	return nil
+}
+
+// Referenced returns the non-null values of the referenced column,
+// in exported order.
+func (e *Exporter) Referenced(ctx context.Context, ref *parse.Reference) ([]generator.Encoded, error) {
+	return e.refs.values(ref), nil
+}
+
+// file returns the file of table, which is created on first use.
+func (e *Exporter) file(table *parse.Table) (*exportFile, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if f, ok := e.files[table.Name]; ok {
+		return f, nil
+	}
+
+	f, err := os.Create(filepath.Join(e.dir, fmt.Sprintf("%s.%s", table.Name, e.format)))
+	if err != nil {
+		return nil, err
+	}
+	w, err := rowWriters[e.format](f, table.Columns)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	e.files[table.Name] = &exportFile{f: f, w: w}
+	return e.files[table.Name], nil
+}
+
+// Load writes the rows of table to its file. Commit is ignored.
+// Values are generated in text format, like a dry run.
+func (e *Exporter) Load(ctx context.Context, table *parse.Table, commit int) (int64, error) {
+	_, args, err := table.CopyFrom()
+	if err != nil {
+		return 0, fmt.Errorf("loader.Exporter.Load: %w", err)
+	}
+
+	f, err := e.file(table)
+	if err != nil {
+		return 0, fmt.Errorf("loader.Exporter.Load: %w for table %q", err, table.Name)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	refs := e.refs.rows(table)
+	enc := newRowEncoder(len(args))
+
+	for i := 0; i < table.Amount; i++ {
+		if err = ctx.Err(); err != nil {
+			return 0, fmt.Errorf("loader.Exporter.Load: %w", err)
+		}
+
+		row, err := enc.encode(args)
+		if err != nil {
+			return 0, fmt.Errorf("loader.Exporter.Load: %w for table %q", err, table.Name)
+		}
+		if err = f.w.row(row); err != nil {
+			return 0, fmt.Errorf("loader.Exporter.Load: %w for table %q", err, table.Name)
+		}
+		refs.add(row)
+	}
+	refs.done()
+
+	return int64(table.Amount), nil
+}
+
+// Tx calls f with the Exporter.
+// Rows written by f are not removed if it returns an error.
+func (e *Exporter) Tx(ctx context.Context, f func(tx Sink) error) error {
+	return f(e)
+}
+
+// Close completes and closes the files of all loaded tables.
+// It returns the first error.
+func (e *Exporter) Close() (err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for name, f := range e.files {
+		if cerr := f.close(); cerr != nil && err == nil {
+			err = fmt.Errorf("loader.Exporter.Close: %w for table %q", cerr, name)
+		}
+	}
+	e.files = make(map[string]*exportFile)
+
+	return err
+}
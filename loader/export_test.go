@@ -0,0 +1,304 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package loader
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/muhlemmer/pg_testdata/parse"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+func Test_csvField(t *testing.T) {
+	tests := []struct {
+		name  string
+		value []byte
+		want  string
+	}{
+		{"Null", nil, ""},
+		{"Empty", []byte{}, `""`},
+		{"Plain", []byte("foo bar"), "foo bar"},
+		{"Quotes", []byte("a,\"b\"\nc"), "\"a,\"\"b\"\"\nc\""},
+		{"End of data", []byte(`\.`), `"\."`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := csvField(tt.value); got != tt.want {
+				t.Errorf("csvField() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+var (
+	writerColumns = []*parse.Column{
+		{Name: "bool_col", Type: parse.BoolType},
+		{Name: "int4_col", Type: parse.Int4Type},
+		{Name: "text_col", Type: parse.TextType},
+	}
+	writerRows = [][][]byte{
+		{[]byte("t"), []byte("1"), []byte("foo")},
+		{[]byte("f"), nil, []byte{}},
+		{nil, []byte("-3"), nil},
+	}
+)
+
+func Test_rowWriters(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			CSVFormat,
+			"bool_col,int4_col,text_col\nt,1,foo\nf,,\"\"\n,-3,\n",
+		},
+		{
+			JSONLFormat,
+			`{"bool_col":true,"int4_col":1,"text_col":"foo"}` + "\n" +
+				`{"bool_col":false,"int4_col":null,"text_col":""}` + "\n" +
+				`{"bool_col":null,"int4_col":-3,"text_col":null}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			s, err := rowWriters[tt.format](&buf, writerColumns)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, row := range writerRows {
+				if err = s.row(row); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err = s.close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("rowWriter =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parquetWriter(t *testing.T) {
+	var buf bytes.Buffer
+
+	s, err := newParquetWriter(&buf, writerColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range writerRows {
+		if err = s.row(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = s.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	pf, err := buffer.NewBufferFile(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr, err := reader.NewParquetColumnReader(pf, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pr.ReadStop()
+
+	if n := pr.GetNumRows(); n != int64(len(writerRows)) {
+		t.Fatalf("parquet rows = %d, want %d", n, len(writerRows))
+	}
+
+	want := [][]interface{}{
+		{true, false, nil},
+		{int32(1), nil, int32(-3)},
+		{"foo", "", nil},
+	}
+	for i, w := range want {
+		got, _, _, err := pr.ReadColumnByIndex(int64(i), int64(len(writerRows)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, w) {
+			t.Errorf("parquet column %q = %v, want %v", writerColumns[i].Name, got, w)
+		}
+	}
+}
+
+func TestNewExporter(t *testing.T) {
+	if _, err := NewExporter(t.TempDir(), "foo"); err == nil {
+		t.Error("NewExporter() expected error")
+	}
+}
+
+// readFiles returns the content of each file in dir, by name.
+func readFiles(t *testing.T, dir string) map[string]string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := make(map[string]string, len(entries))
+	for _, e := range entries {
+		b, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[e.Name()] = string(b)
+	}
+
+	return files
+}
+
+func TestExporter(t *testing.T) {
+	parents := &parse.Table{
+		Name:   "parents",
+		Amount: 3,
+		Before: parse.BeforeTruncate,
+		Columns: []*parse.Column{
+			{
+				Name: "id",
+				Type: parse.Int4Type,
+				Generator: map[parse.ArgName]interface{}{
+					parse.ModeArg:  parse.SequenceMode,
+					parse.StartArg: 1,
+				},
+			},
+		},
+	}
+	children := &parse.Table{
+		Name:      "children",
+		Amount:    3,
+		ChunkSize: 2,
+		Columns: []*parse.Column{
+			{
+				Name: "parent_id",
+				Type: parse.ReferenceType,
+				Generator: map[parse.ArgName]interface{}{
+					parse.TableArg:  "parents",
+					parse.ColumnArg: "id",
+				},
+			},
+		},
+	}
+	conf := &parse.Config{Tables: []*parse.Table{children, parents}}
+
+	dir := t.TempDir()
+	e, err := NewExporter(dir, CSVFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Parents are truncated, while children are appended to.
+	for i := 0; i < 2; i++ {
+		if _, err = New(conf, e).Load(testCtx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := readFiles(t, dir)
+	want := map[string]string{
+		"parents.csv":  "id\n1\n2\n3\n",
+		"children.csv": "parent_id\n1\n1\n1\n1\n1\n1\n",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Exporter files =\n%v\nwant\n%v", got, want)
+	}
+}
+
+// TestExporter_referenced checks that reference columns pick
+// the same values as when collected.
+func TestExporter_referenced(t *testing.T) {
+	conf := &parse.Config{
+		Tables: []*parse.Table{
+			{
+				Name:   "children",
+				Amount: 10,
+				Columns: []*parse.Column{
+					{
+						Name: "parent_id",
+						Seed: 1,
+						Type: parse.ReferenceType,
+						Generator: map[parse.ArgName]interface{}{
+							parse.TableArg:  "parents",
+							parse.ColumnArg: "id",
+							parse.SkewArg:   1.5,
+						},
+					},
+				},
+			},
+			{
+				Name:      "parents",
+				Amount:    10,
+				ChunkSize: 3,
+				Columns: []*parse.Column{
+					{
+						Name:      "id",
+						Seed:      2,
+						Type:      parse.Int4Type,
+						Generator: map[parse.ArgName]interface{}{parse.MinArg: 1, parse.MaxArg: 100},
+					},
+				},
+			},
+		},
+	}
+
+	c := NewCollector()
+	l := New(conf, c)
+	l.Parallel = 4
+	if _, err := l.Load(testCtx); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "parent_id\n"
+	for _, row := range c.Rows("children") {
+		want += fmt.Sprintf("%v\n", row[0])
+	}
+
+	dir := t.TempDir()
+	e, err := NewExporter(dir, CSVFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = New(conf, e).Load(testCtx); err != nil {
+		t.Fatal(err)
+	}
+	if err = e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := readFiles(t, dir)["children.csv"]; got != want {
+		t.Errorf("Exporter children.csv =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,403 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package loader loads the tables of a parsed configuration into a Sink,
+// such as a database or an in-memory Collector.
+package loader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/muhlemmer/pg_testdata/generator"
+	"github.com/muhlemmer/pg_testdata/parse"
+)
+
+// recoverError must be deferred. It recovers a panic with an error,
+// which is set to err prefixed with name.
+// Runtime errors and other panic values are not recovered.
+func recoverError(name string, err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	e, ok := r.(error)
+	if !ok {
+		panic(r)
+	}
+	var re runtime.Error
+	if errors.As(e, &re) {
+		panic(re)
+	}
+
+	*err = fmt.Errorf("%s: %w", name, e)
+}
+
+// Sink receives the rows of the tables loaded by a Loader.
+// Its methods are called concurrently for a Loader with Parallel larger than 1.
+type Sink interface {
+	// ForeignKeys returns the names of the tables referenced by each of the tables,
+	// which are loaded first.
+	ForeignKeys(ctx context.Context, tables []*parse.Table) (map[string][]string, error)
+	// Reset resets the tables before loading, as returned by parse.ResetQueries.
+	// Tables are in insert order.
+	Reset(ctx context.Context, tables []*parse.Table, reset bool) error
+	// Referenced returns all non-null values of a referenced column, in any order.
+	// The Loader sorts them by their text format, so that reference columns
+	// pick the same values from any Sink.
+	Referenced(ctx context.Context, ref *parse.Reference) ([]generator.Encoded, error)
+	// Load the rows of table, in transactions of commit rows.
+	// It returns the amount of loaded rows.
+	Load(ctx context.Context, table *parse.Table, commit int) (int64, error)
+	// Tx calls f with a Sink in a transaction, which is committed if f returns nil
+	// and rolled back otherwise.
+	Tx(ctx context.Context, f func(tx Sink) error) error
+}
+
+// Result of a completely loaded table.
+type Result struct {
+	Table *parse.Table
+	Rows  int64 // Loaded rows, which excludes rows skipped by an on conflict clause.
+}
+
+// String describes the amount of rows loaded into the table.
+// Rows skipped by an on conflict clause are reported separately.
+func (r Result) String() string {
+	switch {
+	case r.Table.OnConflict == nil:
+		return fmt.Sprintf("table %q: %d rows loaded", r.Table.Name, r.Rows)
+	case r.Table.OnConflict.Action == parse.ConflictUpdate:
+		return fmt.Sprintf("table %q: %d rows inserted or updated", r.Table.Name, r.Rows)
+	default:
+		return fmt.Sprintf("table %q: %d rows inserted, %d skipped", r.Table.Name, r.Rows, int64(r.Table.Amount)-r.Rows)
+	}
+}
+
+// Loader loads the tables of a config into a Sink.
+type Loader struct {
+	Config *parse.Config
+	Sink   Sink
+
+	// Parallel is the maximum amount of tables or chunks loaded concurrently.
+	// Defaults to 1.
+	Parallel int
+	// Reset tables which do not set a before strategy, before loading.
+	Reset bool
+	// Report, if set, is called with the result of each table
+	// when it is completely loaded. Calls are not concurrent.
+	Report func(Result)
+
+	mu sync.Mutex
+}
+
+// New returns a Loader of conf into sink, which loads one table at a time.
+func New(conf *parse.Config, sink Sink) *Loader {
+	return &Loader{
+		Config:   conf,
+		Sink:     sink,
+		Parallel: 1,
+	}
+}
+
+func (l *Loader) report(r Result) {
+	if l.Report == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.Report(r)
+}
+
+// copyConfig returns a copy of conf with copies of its tables and columns,
+// as loading sets the referenced values of the columns.
+func copyConfig(conf *parse.Config) *parse.Config {
+	c := *conf
+	c.Tables = make([]*parse.Table, len(conf.Tables))
+
+	for i, table := range conf.Tables {
+		t := *table
+		t.Columns = make([]*parse.Column, len(table.Columns))
+		for j, col := range table.Columns {
+			cc := *col
+			t.Columns[j] = &cc
+		}
+		c.Tables[i] = &t
+	}
+
+	return &c
+}
+
+// Load resets and loads the tables of the config, in the order of
+// parse.Config.InsertOrder with the foreign keys of the Sink.
+// With transaction "all", everything is loaded in a single transaction
+// of the Sink, which does not support Parallel larger than 1.
+// The tables and columns of the config are copied, so a config can be
+// loaded concurrently by multiple Loaders. The Table of each Result is a copy.
+// It returns the result of each table in insert order, or the first error,
+// after all loads are stopped.
+func (l *Loader) Load(ctx context.Context) (results []Result, err error) {
+	defer recoverError("loader.Loader.Load", &err)
+
+	conf := copyConfig(l.Config)
+
+	fks, err := l.Sink.ForeignKeys(ctx, conf.Tables)
+	if err != nil {
+		panic(err)
+	}
+
+	tables, err := conf.InsertOrder(fks)
+	if err != nil {
+		panic(err)
+	}
+	deps, err := conf.Dependencies(fks)
+	if err != nil {
+		panic(err)
+	}
+
+	all, err := conf.RunTransaction()
+	if err != nil {
+		panic(err)
+	}
+	if !all {
+		return l.load(ctx, l.Sink, tables, deps, l.Parallel), nil
+	}
+
+	// A transaction is a single connection, which can not be used concurrently.
+	if l.Parallel > 1 {
+		panic(fmt.Errorf("parallel %d not supported with transaction %q", l.Parallel, parse.AllTransaction))
+	}
+	err = l.Sink.Tx(ctx, func(tx Sink) (err error) {
+		defer recoverError("loader.Loader.Load", &err)
+
+		results = l.load(ctx, tx, tables, deps, 1)
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return results, nil
+}
+
+func (l *Loader) load(ctx context.Context, sink Sink, tables []*parse.Table, deps map[string][]string, parallel int) []Result {
+	if err := sink.Reset(ctx, tables, l.Reset); err != nil {
+		panic(err)
+	}
+
+	return l.loadTables(ctx, sink, tables, deps, parallel)
+}
+
+// loadReferences sets the values of the columns referenced by table,
+// sorted by their text format.
+// It panics if a referenced column does not contain any values,
+// so the referenced table must be loaded first.
+func loadReferences(ctx context.Context, sink Sink, table *parse.Table) {
+	for _, col := range table.Columns {
+		ref, err := col.Reference()
+		if err != nil {
+			panic(fmt.Errorf("loader.loadReferences: %w for table %q", err, table.Name))
+		}
+		if ref == nil {
+			continue
+		}
+
+		var values []generator.Encoded
+		runWithCtxTimeout(ctx, table.MaxDuration.Table, func(ctx context.Context) {
+			if values, err = sink.Referenced(ctx, ref); err != nil {
+				panic(err)
+			}
+		})
+
+		if len(values) == 0 {
+			panic(fmt.Errorf("loader.loadReferences: referenced table %q is empty, for column %q of table %q", ref.Table, col.Name, table.Name))
+		}
+		sort.Slice(values, func(i, j int) bool {
+			return bytes.Compare(values[i].Text, values[j].Text) < 0
+		})
+
+		col.SetReferenced(values)
+	}
+}
+
+// scheduler limits the amount of concurrent loads
+// and keeps the first error, which cancels all other loads.
+type scheduler struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	slots  chan struct{}
+
+	once sync.Once
+	err  error
+}
+
+func newScheduler(ctx context.Context, parallel int) *scheduler {
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	return &scheduler{
+		ctx:    ctx,
+		cancel: cancel,
+		slots:  make(chan struct{}, parallel),
+	}
+}
+
+// fail must be deferred by each goroutine of the scheduler.
+// It recovers a panic with an error and cancels the scheduler.
+// Runtime errors are not recovered.
+func (s *scheduler) fail() {
+	err, _ := recover().(error)
+	if err == nil {
+		return
+	}
+
+	var r runtime.Error
+	if errors.As(err, &r) {
+		panic(r)
+	}
+
+	s.once.Do(func() {
+		s.err = err
+		s.cancel()
+	})
+}
+
+// run f in a free slot. It returns false without running f
+// if the scheduler was canceled.
+func (s *scheduler) run(f func(ctx context.Context)) bool {
+	select {
+	case s.slots <- struct{}{}:
+	case <-s.ctx.Done():
+		return false
+	}
+	defer func() { <-s.slots }()
+
+	if s.ctx.Err() != nil {
+		return false
+	}
+
+	f(s.ctx)
+	return true
+}
+
+// loadTables loads the tables into sink with up to parallel concurrent loads.
+// Each table is loaded after all tables it depends on, as returned by
+// parse.Config.Dependencies, are completely loaded.
+// The chunks of a table are loaded concurrently, each in transactions of
+// the commit rows of the table, as returned by parse.Config.CommitRows.
+// It returns the results in the order of tables,
+// or panics with the first error, after all loads are stopped.
+func (l *Loader) loadTables(ctx context.Context, sink Sink, tables []*parse.Table, deps map[string][]string, parallel int) []Result {
+	s := newScheduler(ctx, parallel)
+	defer s.cancel()
+
+	done := make(map[string]chan struct{}, len(tables))
+	for _, table := range tables {
+		done[table.Name] = make(chan struct{})
+	}
+
+	results := make([]Result, len(tables))
+	var wg sync.WaitGroup
+
+	for i, table := range tables {
+		wg.Add(1)
+
+		go func(i int, table *parse.Table) {
+			defer wg.Done()
+			defer s.fail()
+
+			for _, name := range deps[table.Name] {
+				select {
+				case <-done[name]:
+				case <-s.ctx.Done():
+					return
+				}
+			}
+
+			if !s.run(func(ctx context.Context) { loadReferences(ctx, sink, table) }) {
+				return
+			}
+
+			commit, err := l.Config.CommitRows(table)
+			if err != nil {
+				panic(err)
+			}
+
+			// Chunks copy the referenced values of the table.
+			chunks, err := table.Chunks()
+			if err != nil {
+				panic(err)
+			}
+
+			var cwg sync.WaitGroup
+			completed := make([]bool, len(chunks))
+			loaded := make([]int64, len(chunks))
+
+			for j, chunk := range chunks {
+				cwg.Add(1)
+
+				go func(j int, chunk *parse.Table) {
+					defer cwg.Done()
+					defer s.fail()
+
+					completed[j] = s.run(func(ctx context.Context) {
+						var err error
+						if loaded[j], err = sink.Load(ctx, chunk, commit); err != nil {
+							panic(err)
+						}
+					})
+				}(j, chunk)
+			}
+			cwg.Wait()
+
+			var rows int64
+			for j, ok := range completed {
+				if !ok {
+					return
+				}
+				rows += loaded[j]
+			}
+			results[i] = Result{Table: table, Rows: rows}
+			l.report(results[i])
+			close(done[table.Name])
+		}(i, table)
+	}
+
+	wg.Wait()
+
+	if s.err != nil {
+		panic(s.err)
+	}
+	if err := ctx.Err(); err != nil {
+		panic(fmt.Errorf("loader.loadTables: %w", err))
+	}
+
+	return results
+}
@@ -0,0 +1,409 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package loader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/muhlemmer/pg_testdata/generator"
+	"github.com/muhlemmer/pg_testdata/parse"
+)
+
+func Test_recoverError(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     interface{}
+		wantErr   error
+		wantPanic bool
+	}{
+		{"No panic", nil, nil, false},
+		{"Error", errors.New("foo"), errors.New("test: foo"), false},
+		{"Runtime error", func() (err error) {
+			defer func() { err, _ = recover().(error) }()
+			var m map[string]int
+			m["foo"] = 1
+			return
+		}(), nil, true},
+		{"Other value", "foo", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				err      error
+				panicked bool
+			)
+
+			func() {
+				defer func() { panicked = recover() != nil }()
+				func() {
+					defer recoverError("test", &err)
+					if tt.value != nil {
+						panic(tt.value)
+					}
+				}()
+			}()
+
+			if panicked != tt.wantPanic {
+				t.Errorf("recoverError() panicked = %v, want %v", panicked, tt.wantPanic)
+			}
+			if fmt.Sprint(err) != fmt.Sprint(tt.wantErr) {
+				t.Errorf("recoverError() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResult_String(t *testing.T) {
+	tests := []struct {
+		name       string
+		onConflict *parse.OnConflict
+		want       string
+	}{
+		{
+			"Loaded",
+			nil,
+			`table "articles": 8 rows loaded`,
+		},
+		{
+			"Skipped",
+			&parse.OnConflict{Action: parse.ConflictNothing},
+			`table "articles": 8 rows inserted, 2 skipped`,
+		},
+		{
+			"Updated",
+			&parse.OnConflict{Action: parse.ConflictUpdate},
+			`table "articles": 8 rows inserted or updated`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Result{
+				Table: &parse.Table{Name: "articles", Amount: 10, OnConflict: tt.onConflict},
+				Rows:  8,
+			}
+
+			if got := r.String(); got != tt.want {
+				t.Errorf("Result.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_loadTables(t *testing.T) {
+	ectx, cancel := context.WithCancel(testCtx)
+	cancel()
+
+	table := func(name string, chunkSize int) *parse.Table {
+		return &parse.Table{
+			Name:      name,
+			Amount:    10,
+			ChunkSize: chunkSize,
+			MaxDuration: parse.TableDurations{
+				Table: 10 * time.Second,
+				Exec:  1 * time.Second,
+			},
+			Columns: []*parse.Column{
+				{
+					Name: "bool_col",
+					Type: parse.BoolType,
+					Generator: map[parse.ArgName]interface{}{
+						parse.ProbabilityArg: 100,
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		tables   []*parse.Table
+		deps     map[string][]string
+		parallel int
+		want     []int64
+		wantErr  bool
+	}{
+		{
+			"Context error",
+			ectx,
+			[]*parse.Table{table("unit_tests", 0)},
+			nil,
+			1,
+			nil,
+			true,
+		},
+		{
+			"Chunks error",
+			testCtx,
+			[]*parse.Table{table("unit_tests", -1)},
+			nil,
+			1,
+			nil,
+			true,
+		},
+		{
+			"Chunks in transaction",
+			testCtx,
+			[]*parse.Table{func() *parse.Table {
+				t := table("unit_tests", 3)
+				t.Transaction = parse.AllTransaction
+				return t
+			}()},
+			nil,
+			1,
+			nil,
+			true,
+		},
+		{
+			"Dependency error",
+			testCtx,
+			[]*parse.Table{table("error_tests", 0), table("unit_tests", 0)},
+			map[string][]string{"unit_tests": {"error_tests"}},
+			2,
+			nil,
+			true,
+		},
+		{
+			"Sequential",
+			testCtx,
+			[]*parse.Table{table("unit_tests", 3)},
+			nil,
+			0,
+			[]int64{10},
+			false,
+		},
+		{
+			"Parallel",
+			testCtx,
+			[]*parse.Table{table("unit_tests", 3)},
+			nil,
+			4,
+			[]int64{10},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reported int
+
+			l := New(&parse.Config{}, NewDBSink(testDB))
+			l.Report = func(Result) { reported++ }
+
+			var got []int64
+			err := func() (err error) {
+				defer func() { err, _ = recover().(error) }()
+
+				for _, r := range l.loadTables(tt.ctx, l.Sink, tt.tables, tt.deps, tt.parallel) {
+					got = append(got, r.Rows)
+				}
+				return
+			}()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("loadTables() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadTables() rows = %v, want %v", got, tt.want)
+			}
+			if reported != len(tt.want) {
+				t.Errorf("loadTables() reported %d tables, want %d", reported, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestLoader_Load(t *testing.T) {
+	conf := func(transaction parse.Transaction) *parse.Config {
+		return &parse.Config{
+			Transaction: transaction,
+			Tables: []*parse.Table{
+				{
+					Name:   "reference_children",
+					Amount: 5,
+					MaxDuration: parse.TableDurations{
+						Table: 10 * time.Second,
+						Exec:  1 * time.Second,
+					},
+					Columns: []*parse.Column{
+						{
+							Name: "parent_id",
+							Type: parse.ReferenceType,
+							Generator: map[parse.ArgName]interface{}{
+								parse.TableArg:  "reference_parents",
+								parse.ColumnArg: "id",
+							},
+						},
+					},
+				},
+				{
+					Name:   "reference_parents",
+					Amount: 3,
+					Before: parse.BeforeTruncateCascade,
+					MaxDuration: parse.TableDurations{
+						Table: 10 * time.Second,
+						Exec:  1 * time.Second,
+					},
+					Columns: []*parse.Column{
+						{
+							Name: "id",
+							Type: parse.Int4Type,
+							Generator: map[parse.ArgName]interface{}{
+								parse.ModeArg:  parse.SequenceMode,
+								parse.StartArg: 8001,
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		conf     *parse.Config
+		parallel int
+		want     []string
+		wantErr  bool
+	}{
+		{
+			"Transaction error",
+			conf("foo"),
+			1,
+			nil,
+			true,
+		},
+		{
+			"Parallel transaction",
+			conf(parse.AllTransaction),
+			2,
+			nil,
+			true,
+		},
+		{
+			"Parallel",
+			conf(parse.NoTransaction),
+			2,
+			[]string{
+				`table "reference_parents": 3 rows loaded`,
+				`table "reference_children": 5 rows loaded`,
+			},
+			false,
+		},
+		{
+			"Transaction",
+			conf(parse.AllTransaction),
+			1,
+			[]string{
+				`table "reference_parents": 3 rows loaded`,
+				`table "reference_children": 5 rows loaded`,
+			},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(tt.conf, NewDBSink(testDB))
+			l.Parallel = tt.parallel
+
+			results, err := l.Load(testCtx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Loader.Load() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			var got []string
+			for _, r := range results {
+				got = append(got, r.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Loader.Load() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_copyConfig(t *testing.T) {
+	conf := &parse.Config{
+		Transaction: parse.AllTransaction,
+		Tables: []*parse.Table{
+			{
+				Name:    "articles",
+				Amount:  10,
+				Columns: []*parse.Column{{Name: "id", Type: parse.Int4Type}},
+			},
+		},
+	}
+
+	got := copyConfig(conf)
+	if !reflect.DeepEqual(got, conf) {
+		t.Errorf("copyConfig() = %v, want %v", got, conf)
+	}
+	if got.Tables[0] == conf.Tables[0] || got.Tables[0].Columns[0] == conf.Tables[0].Columns[0] {
+		t.Error("copyConfig() shares tables or columns")
+	}
+}
+
+// unsortedSink returns referenced values in reverse order.
+type unsortedSink struct {
+	*Collector
+}
+
+func (s unsortedSink) Referenced(ctx context.Context, ref *parse.Reference) ([]generator.Encoded, error) {
+	return []generator.Encoded{{Text: []byte("3")}, {Text: []byte("2")}, {Text: []byte("1")}}, nil
+}
+
+func Test_loadReferences_sorted(t *testing.T) {
+	table := &parse.Table{
+		Name:   "children",
+		Amount: 10,
+		Columns: []*parse.Column{
+			{
+				Name: "parent_id",
+				Seed: 1,
+				Type: parse.ReferenceType,
+				Generator: map[parse.ArgName]interface{}{
+					parse.TableArg:  "parents",
+					parse.ColumnArg: "id",
+					parse.SkewArg:   2,
+				},
+			},
+		},
+	}
+	loadReferences(testCtx, unsortedSink{NewCollector()}, table)
+
+	_, args, err := table.CopyFrom()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := generator.NewReference(1, 0, []generator.Encoded{{Text: []byte("1")}, {Text: []byte("2")}, {Text: []byte("3")}}, 2)
+
+	for i := 0; i < table.Amount; i++ {
+		if got, w := args[0].(generator.Value).Get(), want.Get(); got != w {
+			t.Fatalf("loadReferences() row %d = %v, want %v", i, got, w)
+		}
+	}
+}
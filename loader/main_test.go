@@ -0,0 +1,99 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package loader
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+var (
+	testCtx context.Context
+	testDB  *pgxpool.Pool
+)
+
+// testSchema holds the test tables of this package,
+// so they do not interfere with the tests of other packages.
+const testSchema = "loader_tests"
+
+func testDSN() string {
+	params := map[string]string{
+		"PGHOST":     "db",
+		"PGDATABASE": "testdata",
+		"PGUSER":     "testdata",
+		"PGPORT":     "5432",
+	}
+
+	for k := range params {
+		if v, ok := os.LookupEnv(k); ok {
+			params[k] = v
+		}
+	}
+
+	const dsnFmt = "host=%s dbname=%s user=%s port=%s search_path=%s"
+
+	return fmt.Sprintf(dsnFmt, params["PGHOST"], params["PGDATABASE"], params["PGUSER"], params["PGPORT"], testSchema)
+}
+
+func execQuerySlice(ctx context.Context, sqls []string) {
+	for _, sql := range sqls {
+		runWithCtxTimeout(ctx, 1*time.Second, func(c context.Context) {
+			if _, err := testDB.Exec(c, sql); err != nil {
+				log.Fatalf("testing.execQuerySlice: %v", err)
+			}
+		})
+	}
+}
+
+func TestMain(m *testing.M) {
+	var cancel context.CancelFunc
+	testCtx, cancel = context.WithTimeout(context.Background(), 30*time.Second)
+
+	runWithCtxTimeout(testCtx, 1*time.Second, func(c context.Context) {
+		var err error
+		if testDB, err = pgxpool.Connect(c, testDSN()); err != nil {
+			log.Fatalf("testing.TestMain: %v", err)
+		}
+	})
+
+	createTablesSQL, err := os.ReadFile("../testdata/create.sql")
+	if err != nil {
+		log.Fatalf("testing.TestMain: %v", err)
+	}
+
+	execQuerySlice(testCtx, []string{
+		fmt.Sprintf("drop schema if exists %s cascade;", testSchema),
+		fmt.Sprintf("create schema %s;", testSchema),
+	})
+	execQuerySlice(testCtx, strings.SplitAfter(string(createTablesSQL), ";"))
+
+	exit := m.Run()
+
+	cancel()
+	os.Exit(exit)
+}
@@ -32,15 +32,31 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/muhlemmer/pg_testdata/loader"
 	"github.com/muhlemmer/pg_testdata/parse"
 )
 
+// options of the run command.
+type options struct {
+	parallel int    // Maximum amount of tables or chunks loaded concurrently, defaults to 1.
+	reset    bool   // Truncate tables which do not set a before strategy, before loading.
+	dryRun   bool   // Write the statements to output, instead of loading the database.
+	output   string // Output file of a dry run, defaults to stdout. Output directory of a file format.
+	format   string // Format of a dry run, loader.SQLFormat or loader.CopyFormat. Or a file format, which does not connect to the database.
+}
+
 var (
 	configFile string
+	runOptions options
 )
 
 func init() {
 	flag.StringVar(&configFile, "conf", "pg_testdata.yml", "YAML config file with schema definitions")
+	flag.IntVar(&runOptions.parallel, "parallel", 1, "Maximum amount of tables or chunks loaded concurrently")
+	flag.BoolVar(&runOptions.reset, "reset", false, "Truncate tables which do not set a before strategy, before loading")
+	flag.BoolVar(&runOptions.dryRun, "dry-run", false, "Write the statements to output, without connecting to the database")
+	flag.StringVar(&runOptions.output, "output", "", "Output file of a dry run, defaults to stdout. Output directory of a file format, defaults to the working directory")
+	flag.StringVar(&runOptions.format, "format", loader.SQLFormat, "Format of a dry run: sql or copy. Or a file format, written without connecting to the database: csv, jsonl or parquet")
 }
 
 func runWithCtxTimeout(ctx context.Context, d time.Duration, f func(context.Context)) {
@@ -50,10 +66,19 @@ func runWithCtxTimeout(ctx context.Context, d time.Duration, f func(context.Cont
 	f(ctx)
 }
 
-func connectDB(ctx context.Context, dsn string) (pool *pgxpool.Pool) {
+// connectDB connects a pool to the database.
+// The pool allows for at least minConns connections.
+func connectDB(ctx context.Context, dsn string, minConns int) (pool *pgxpool.Pool) {
+	config, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		panic(fmt.Errorf("main.connectDB: %w", err))
+	}
+	if int(config.MaxConns) < minConns {
+		config.MaxConns = int32(minConns)
+	}
+
 	runWithCtxTimeout(ctx, 5*time.Second, func(c context.Context) {
-		var err error
-		pool, err = pgxpool.Connect(ctx, dsn)
+		pool, err = pgxpool.ConnectConfig(ctx, config)
 		if err != nil {
 			panic(fmt.Errorf("main.connectDB: %w", err))
 		}
@@ -62,32 +87,43 @@ func connectDB(ctx context.Context, dsn string) (pool *pgxpool.Pool) {
 	return pool
 }
 
-func acquireConn(ctx context.Context, pool *pgxpool.Pool) (conn *pgxpool.Conn) {
-	runWithCtxTimeout(ctx, 5*time.Second, func(ctx context.Context) {
-		var err error
+// fatal must be deferred. It recovers a panic with an error,
+// which is logged and results in an exit code of 1.
+// Runtime errors are not recovered.
+func fatal(exit *int) {
+	err, _ := recover().(error)
+	if err != nil {
+		log.Printf("FATAL ERROR: %v", err)
+		*exit = 1
+	}
 
-		conn, err = pool.Acquire(ctx)
-		if err != nil {
-			panic(fmt.Errorf("main.acquire: %w", err))
-		}
-	})
+	var r runtime.Error
+	if errors.As(err, &r) {
+		panic(r)
+	}
+}
 
-	return conn
+// check returns an error for a dry run format or an output,
+// which are not used when loading the database.
+func (opts options) check() error {
+	if opts.dryRun || loader.ExportFormat(opts.format) {
+		return nil
+	}
+	if opts.format != "" && opts.format != loader.SQLFormat {
+		return fmt.Errorf("main.run: format %q requires -dry-run", opts.format)
+	}
+	if opts.output != "" {
+		return errors.New("main.run: output requires -dry-run or a file format")
+	}
+	return nil
 }
 
-func run(cf string) (exit int) {
-	defer func() {
-		err, _ := recover().(error)
-		if err != nil {
-			log.Printf("FATAL ERROR: %v", err)
-			exit = 1
-		}
+func run(cf string, opts options) (exit int) {
+	defer fatal(&exit)
 
-		var r runtime.Error
-		if errors.As(err, &r) {
-			panic(r)
-		}
-	}()
+	if err := opts.check(); err != nil {
+		panic(err)
+	}
 
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
 	defer cancel()
@@ -97,16 +133,35 @@ func run(cf string) (exit int) {
 		panic(err)
 	}
 
-	pool := connectDB(ctx, conf.DSN)
+	if loader.ExportFormat(opts.format) {
+		exportFiles(ctx, conf, opts)
+		return 0
+	}
+	if opts.dryRun {
+		writeDryRun(ctx, conf, opts)
+		return 0
+	}
+
+	pool := connectDB(ctx, conf.DSN, opts.parallel)
+	validateSchema(ctx, pool, conf)
+
+	l := loader.New(conf, loader.NewDBSink(pool))
+	l.Parallel = opts.parallel
+	l.Reset = opts.reset
+	l.Report = func(r loader.Result) { log.Print(r) }
 
-	for _, table := range conf.Tables {
-		execInserts(ctx, pool, table)
+	if _, err = l.Load(ctx); err != nil {
+		panic(err)
 	}
 
 	return 0
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "init" {
+		os.Exit(initCommand(os.Args[2:], os.Stdout))
+	}
+
 	flag.Parse()
-	os.Exit(run(configFile))
+	os.Exit(run(configFile, runOptions))
 }
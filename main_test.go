@@ -28,12 +28,14 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/muhlemmer/pg_testdata/loader"
 )
 
 var (
@@ -81,7 +83,7 @@ func TestMain(m *testing.M) {
 	testCtx, cancel = context.WithTimeout(context.Background(), 30*time.Second)
 
 	runWithCtxTimeout(testCtx, 1*time.Second, func(c context.Context) {
-		testDB = connectDB(c, testDSN())
+		testDB = connectDB(c, testDSN(), 0)
 	})
 
 	execQuerySlice(testCtx, strings.SplitAfter(dropTablesSQL, ";"))
@@ -95,18 +97,27 @@ func TestMain(m *testing.M) {
 
 func Test_connectDB(t *testing.T) {
 	tests := []struct {
-		name    string
-		dsn     string
-		wantErr bool
+		name     string
+		dsn      string
+		minConns int
+		wantErr  bool
 	}{
 		{
 			"Failure",
 			"foo",
+			0,
 			true,
 		},
 		{
 			"Success",
 			testDSN(),
+			0,
+			false,
+		},
+		{
+			"Minimum connections",
+			testDSN(),
+			64,
 			false,
 		},
 	}
@@ -117,7 +128,10 @@ func Test_connectDB(t *testing.T) {
 					err, _ = recover().(error)
 				}()
 
-				pool := connectDB(testCtx, tt.dsn)
+				pool := connectDB(testCtx, tt.dsn, tt.minConns)
+				if max := int(pool.Config().MaxConns); max < tt.minConns {
+					t.Errorf("connectDB() MaxConns = %d, want at least %d", max, tt.minConns)
+				}
 				return pool.Ping(testCtx)
 			}()
 
@@ -129,64 +143,101 @@ func Test_connectDB(t *testing.T) {
 	}
 }
 
-func Test_acquireConn(t *testing.T) {
-	ectx, cancel := context.WithCancel(testCtx)
-	cancel()
-
+func Test_options_check(t *testing.T) {
 	tests := []struct {
 		name    string
-		ctx     context.Context
+		opts    options
 		wantErr bool
 	}{
-		{
-			"Context error",
-			ectx,
-			true,
-		},
-		{
-			"Succes",
-			testCtx,
-			false,
-		},
+		{"Default", options{}, false},
+		{"SQL", options{format: loader.SQLFormat}, false},
+		{"Copy", options{format: loader.CopyFormat}, true},
+		{"Output", options{output: "out.sql"}, true},
+		{"Dry run", options{dryRun: true, format: loader.CopyFormat, output: "out.sql"}, false},
+		{"Export", options{format: loader.CSVFormat, output: "out"}, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := func() (err error) {
-				defer func() { err, _ = recover().(error) }()
-				conn := acquireConn(tt.ctx, testDB)
-				defer conn.Release()
-
-				return conn.Ping(testCtx)
-			}()
-
-			if (err != nil) != tt.wantErr {
-				t.Errorf("acquireConn() error = %v, wantErr %v", err, tt.wantErr)
-				return
+			if err := tt.opts.check(); (err != nil) != tt.wantErr {
+				t.Errorf("options.check() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
 
 func Test_run(t *testing.T) {
+	dryRunOutput := filepath.Join(t.TempDir(), "out.sql")
+
 	tests := []struct {
 		name     string
 		cf       string
+		opts     options
 		wantExit int
 	}{
 		{
 			"Config error",
 			"testdata/invalid.yml",
+			options{},
 			1,
 		},
 		{
 			"Success",
 			"testdata/unit_test.yml",
+			options{},
+			0,
+		},
+		{
+			"References",
+			"testdata/reference_test.yml",
+			options{},
+			0,
+		},
+		{
+			"References with copy",
+			"testdata/reference_copy_test.yml",
+			options{reset: true},
+			0,
+		},
+		{
+			"Transaction",
+			"testdata/transaction_test.yml",
+			options{},
+			0,
+		},
+		{
+			"Copy without dry run",
+			"testdata/regression_test.yml",
+			options{format: loader.CopyFormat},
+			1,
+		},
+		{
+			"Dry run",
+			"testdata/regression_test.yml",
+			options{dryRun: true, output: dryRunOutput},
+			0,
+		},
+		{
+			"Dry run references",
+			"testdata/reference_test.yml",
+			options{dryRun: true, output: dryRunOutput},
+			0,
+		},
+		{
+			"Dry run error",
+			"testdata/regression_test.yml",
+			options{dryRun: true, format: "foo", output: dryRunOutput},
+			1,
+		},
+		{
+			"Export",
+			"testdata/regression_test.yml",
+			options{format: loader.JSONLFormat, output: t.TempDir()},
 			0,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if gotExit := run(tt.cf); gotExit != tt.wantExit {
+			if gotExit := run(tt.cf, tt.opts); gotExit != tt.wantExit {
 				t.Errorf("run() = %v, want %v", gotExit, tt.wantExit)
 			}
 		})
@@ -204,16 +255,14 @@ func init() {
 	flag.BoolVar(&recordRegressionData, "rec_reg_data", false, "Record regression data")
 }
 
-func Test_regression(t *testing.T) {
-	exit := run("testdata/regression_test.yml")
-	if exit != 0 {
-		t.Fatal("regression test failed")
+// queryRegressionData returns all data from table, by column.
+// Rows are in insertion order, unless orderBy is set.
+func queryRegressionData(t *testing.T, table, orderBy string) (got []regressionColumn) {
+	sql := fmt.Sprintf("select * from %s;", table)
+	if orderBy != "" {
+		sql = fmt.Sprintf("select * from %s order by %s;", table, orderBy)
 	}
 
-	const sql = "select * from regression_tests;"
-
-	var got []regressionColumn
-
 	runWithCtxTimeout(testCtx, 5*time.Second, func(c context.Context) {
 		rows, err := testDB.Query(c, sql)
 		if err != nil {
@@ -239,7 +288,33 @@ func Test_regression(t *testing.T) {
 		}
 	})
 
-	const regressionDataFile = "testdata/regression.json"
+	return got
+}
+
+const regressionDataFile = "testdata/regression.json"
+
+func readRegressionData(t *testing.T) (want []regressionColumn) {
+	file, err := os.Open(regressionDataFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	dec := json.NewDecoder(file)
+	if err = dec.Decode(&want); err != nil {
+		t.Fatal(err)
+	}
+
+	return want
+}
+
+func Test_regression(t *testing.T) {
+	exit := run("testdata/regression_test.yml", options{})
+	if exit != 0 {
+		t.Fatal("regression test failed")
+	}
+
+	got := queryRegressionData(t, "regression_tests", "")
 
 	if recordRegressionData {
 		file, err := os.Create(regressionDataFile)
@@ -260,19 +335,90 @@ func Test_regression(t *testing.T) {
 		file.Close()
 	}
 
-	file, err := os.Open(regressionDataFile)
-	if err != nil {
-		t.Fatal(err)
+	want := readRegressionData(t)
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("regression got =\n%v\nwant\n%v", got, want)
 	}
-	defer file.Close()
+}
 
-	dec := json.NewDecoder(file)
-	var want []regressionColumn
-	if err = dec.Decode(&want); err != nil {
-		t.Fatal(err)
+// Test_regression_copy checks that COPY loads the same data as inserts.
+func Test_regression_copy(t *testing.T) {
+	exit := run("testdata/regression_copy_test.yml", options{})
+	if exit != 0 {
+		t.Fatal("regression copy test failed")
 	}
 
+	got := queryRegressionData(t, "regression_copy_tests", "")
+	want := readRegressionData(t)
+
 	if !reflect.DeepEqual(got, want) {
 		t.Errorf("regression got =\n%v\nwant\n%v", got, want)
 	}
 }
+
+// Test_regression_batch checks that batched inserts load the same data as single row inserts.
+func Test_regression_batch(t *testing.T) {
+	exit := run("testdata/regression_batch_test.yml", options{})
+	if exit != 0 {
+		t.Fatal("regression batch test failed")
+	}
+
+	got := queryRegressionData(t, "regression_batch_tests", "")
+	want := readRegressionData(t)
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("regression got =\n%v\nwant\n%v", got, want)
+	}
+}
+
+// Test_regression_parallel checks that the chunks of a table load the same data,
+// regardless of the amount of parallel loads.
+func Test_regression_parallel(t *testing.T) {
+	var results [][]regressionColumn
+
+	for _, parallel := range []int{1, 4} {
+		execQuerySlice(testCtx, []string{"truncate regression_parallel_tests;"})
+
+		exit := run("testdata/regression_parallel_test.yml", options{parallel: parallel})
+		if exit != 0 {
+			t.Fatalf("regression parallel test failed with %d parallel loads", parallel)
+		}
+
+		results = append(results, queryRegressionData(t, "regression_parallel_tests", "id"))
+	}
+
+	if !reflect.DeepEqual(results[0], results[1]) {
+		t.Errorf("regression parallel got =\n%v\nwant\n%v", results[1], results[0])
+	}
+}
+
+// Test_run_transaction checks that a run in a single transaction
+// is rolled back completely on error.
+func Test_run_transaction(t *testing.T) {
+	if exit := run("testdata/transaction_error_test.yml", options{}); exit != 1 {
+		t.Fatalf("run() = %d, want 1", exit)
+	}
+	if exit := run("testdata/transaction_test.yml", options{parallel: 2}); exit != 1 {
+		t.Fatalf("run() with parallel = %d, want 1", exit)
+	}
+
+	var n int
+	runWithCtxTimeout(testCtx, time.Second, func(ctx context.Context) {
+		if err := testDB.QueryRow(ctx, "select count(*) from reference_parents where id > 6000;").Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if n != 0 {
+		t.Errorf("run() left %d rows after rollback", n)
+	}
+}
+
+// Test_run_reset checks that tables can be loaded repeatedly with reset.
+func Test_run_reset(t *testing.T) {
+	for i := 0; i < 2; i++ {
+		if exit := run("testdata/reference_test.yml", options{reset: true}); exit != 0 {
+			t.Fatalf("run() = %d in run %d, want 0", exit, i)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/muhlemmer/pg_testdata/generator"
+)
+
+// choiceEntry is a converted ChoiceArg value, with its weight.
+type choiceEntry struct {
+	value  interface{}
+	weight float64
+}
+
+// choices returns the values and weights of the ChoiceArg,
+// where each value is passed through convert.
+// A list of values results in nil weights, meaning equal chances.
+// A map holds values with their weight. As map order is not defined,
+// the entries are sorted by value, to keep the generated data deterministic.
+func (c *Column) choices(convert func(v interface{}) interface{}) (values []interface{}, weights []float64) {
+	switch arg := c.Generator[ChoiceArg].(type) {
+	case []interface{}:
+		for _, v := range arg {
+			values = append(values, convert(v))
+		}
+
+	case map[interface{}]interface{}:
+		entries := make([]choiceEntry, 0, len(arg))
+
+		var total float64
+		for k, w := range arg {
+			e := choiceEntry{value: convert(k)}
+
+			switch w := w.(type) {
+			case int:
+				e.weight = float64(w)
+			case float64:
+				e.weight = w
+			default:
+				c.panic(fmt.Errorf("%q weight of %v incorrect type: %T, expected: float64", ChoiceArg, k, w))
+			}
+			if e.weight < 0 || math.IsInf(e.weight, 0) || math.IsNaN(e.weight) {
+				c.panic(fmt.Errorf("%q weight %g of %v out of range", ChoiceArg, e.weight, k))
+			}
+
+			total += e.weight
+			entries = append(entries, e)
+		}
+		if total <= 0 {
+			c.panic(fmt.Errorf("%q weights sum to zero", ChoiceArg))
+		}
+
+		sort.Slice(entries, func(i, j int) bool {
+			switch v := entries[i].value.(type) {
+			case int64:
+				return v < entries[j].value.(int64)
+			default:
+				return v.(string) < entries[j].value.(string)
+			}
+		})
+
+		for _, e := range entries {
+			values = append(values, e.value)
+			weights = append(weights, e.weight)
+		}
+
+	default:
+		c.panic(fmt.Errorf("%q incorrect type: %T, expected: list or map", ChoiceArg, arg))
+	}
+
+	if len(values) == 0 {
+		c.panic(fmt.Errorf("empty %q", ChoiceArg))
+	}
+
+	return values, weights
+}
+
+// stringChoices returns the ChoiceArg values as strings.
+// When length is not 0, no value may exceed it.
+// YAML decodes unquoted yes, no, on, off, y, n, true and false as bools,
+// without the original spelling. Such values must be quoted, like init does.
+func (c *Column) stringChoices(length int64) generator.StringSource {
+	values, weights := c.choices(func(v interface{}) interface{} {
+		if _, ok := v.(bool); ok {
+			c.panic(fmt.Errorf("%q value %v incorrect type: bool, expected: string, quote the value in YAML", ChoiceArg, v))
+		}
+		s, ok := v.(string)
+		if !ok {
+			c.panic(fmt.Errorf("%q value %v incorrect type: %T, expected: string", ChoiceArg, v, v))
+		}
+		return s
+	})
+
+	strs := make([]string, len(values))
+	for i, v := range values {
+		strs[i] = v.(string)
+	}
+
+	src := generator.ChoiceString(strs, weights)
+	if n := generator.MaxLength(src); length > 0 && int64(n) > length {
+		c.panic(fmt.Errorf("%q value of %d characters longer than %q %d", ChoiceArg, n, LengthArg, length))
+	}
+
+	return src
+}
+
+// intChoices returns the ChoiceArg values as integers,
+// which must be between lower and upper.
+func (c *Column) intChoices(tp TypeName, lower, upper int64) ([]int64, []float64) {
+	values, weights := c.choices(func(v interface{}) interface{} {
+		var i int64
+
+		switch v := v.(type) {
+		case int:
+			i = int64(v)
+		case int64:
+			i = v
+		default:
+			c.panic(fmt.Errorf("%q value %v incorrect type: %T, expected: int", ChoiceArg, v, v))
+		}
+
+		if i < lower || i > upper {
+			c.panic(fmt.Errorf("value %d out of range for type %q", i, tp))
+		}
+		return i
+	})
+
+	ints := make([]int64, len(values))
+	for i, v := range values {
+		ints[i] = v.(int64)
+	}
+
+	return ints, weights
+}
+
+// choiceGenerator panics if Type does not support the ChoiceArg,
+// or if it is combined with SequenceMode.
+func (c *Column) choiceGenerator() generator.Value {
+	if c.mode() == SequenceMode {
+		c.panic(fmt.Errorf("%q can not be combined with %q %q", ChoiceArg, ModeArg, SequenceMode))
+	}
+
+	switch c.Type {
+	case TextType, EnumType:
+		return generator.NewText(c.Seed, c.NullProbability, c.stringChoices(0))
+	case VarcharType:
+		return generator.NewVarchar(c.Seed, c.NullProbability, c.stringChoices(c.typeLength(VarcharType)))
+	case CharType:
+		return generator.NewBPChar(c.Seed, c.NullProbability, c.stringChoices(c.typeLength(CharType)))
+	case Int2Type:
+		values, weights := c.intChoices(Int2Type, math.MinInt16, math.MaxInt16)
+
+		ints := make([]int16, len(values))
+		for i, v := range values {
+			ints[i] = int16(v)
+		}
+		return generator.NewInt2Choice(c.Seed, c.NullProbability, ints, weights)
+	case Int4Type:
+		values, weights := c.intChoices(Int4Type, math.MinInt32, math.MaxInt32)
+
+		ints := make([]int32, len(values))
+		for i, v := range values {
+			ints[i] = int32(v)
+		}
+		return generator.NewInt4Choice(c.Seed, c.NullProbability, ints, weights)
+	case Int8Type:
+		values, weights := c.intChoices(Int8Type, math.MinInt64, math.MaxInt64)
+		return generator.NewInt8Choice(c.Seed, c.NullProbability, values, weights)
+	default:
+		c.panic(fmt.Errorf("%q unsupported for type %q", ChoiceArg, c.Type))
+		return nil
+	}
+}
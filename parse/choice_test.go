@@ -0,0 +1,202 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/muhlemmer/pg_testdata/generator"
+)
+
+func Test_column_choiceGenerator(t *testing.T) {
+	tests := []struct {
+		name      string
+		Type      TypeName
+		Generator map[ArgName]interface{}
+		want      generator.Value
+		wantErr   bool
+	}{
+		{
+			"unsupported type",
+			BoolType,
+			map[ArgName]interface{}{ChoiceArg: []interface{}{"true"}},
+			nil,
+			true,
+		},
+		{
+			"enum missing choice",
+			EnumType,
+			nil,
+			nil,
+			true,
+		},
+		{
+			"sequence mode",
+			Int4Type,
+			map[ArgName]interface{}{ChoiceArg: []interface{}{1, 2}, ModeArg: SequenceMode},
+			nil,
+			true,
+		},
+		{
+			"incorrect type",
+			TextType,
+			map[ArgName]interface{}{ChoiceArg: "foo"},
+			nil,
+			true,
+		},
+		{
+			"empty",
+			TextType,
+			map[ArgName]interface{}{ChoiceArg: []interface{}{}},
+			nil,
+			true,
+		},
+		{
+			"incorrect value type",
+			TextType,
+			map[ArgName]interface{}{ChoiceArg: []interface{}{"foo", 1}},
+			nil,
+			true,
+		},
+		{
+			"unquoted yaml bool",
+			EnumType,
+			map[ArgName]interface{}{ChoiceArg: []interface{}{true, false}},
+			nil,
+			true,
+		},
+		{
+			"incorrect weight type",
+			EnumType,
+			map[ArgName]interface{}{ChoiceArg: map[interface{}]interface{}{"foo": "bar"}},
+			nil,
+			true,
+		},
+		{
+			"negative weight",
+			EnumType,
+			map[ArgName]interface{}{ChoiceArg: map[interface{}]interface{}{"foo": 1, "bar": -1}},
+			nil,
+			true,
+		},
+		{
+			"zero weights",
+			EnumType,
+			map[ArgName]interface{}{ChoiceArg: map[interface{}]interface{}{"foo": 0, "bar": 0.0}},
+			nil,
+			true,
+		},
+		{
+			"varchar too long",
+			VarcharType,
+			map[ArgName]interface{}{ChoiceArg: []interface{}{"foo", "foobar"}, LengthArg: 5},
+			nil,
+			true,
+		},
+		{
+			"int2 out of range",
+			Int2Type,
+			map[ArgName]interface{}{ChoiceArg: []interface{}{1, 70000}},
+			nil,
+			true,
+		},
+		{
+			"int8 incorrect value type",
+			Int8Type,
+			map[ArgName]interface{}{ChoiceArg: []interface{}{1, 2.5}},
+			nil,
+			true,
+		},
+		{
+			"text list",
+			TextType,
+			map[ArgName]interface{}{ChoiceArg: []interface{}{"foo", "bar"}},
+			generator.NewText(1, 2, generator.ChoiceString([]string{"foo", "bar"}, nil)),
+			false,
+		},
+		{
+			"enum weighted",
+			EnumType,
+			map[ArgName]interface{}{ChoiceArg: map[interface{}]interface{}{"active": 80, "suspended": 15, "deleted": 4.5}},
+			generator.NewText(1, 2, generator.ChoiceString([]string{"active", "deleted", "suspended"}, []float64{80, 4.5, 15})),
+			false,
+		},
+		{
+			"varchar",
+			VarcharType,
+			map[ArgName]interface{}{ChoiceArg: []interface{}{"foo", "bar"}, LengthArg: 3},
+			generator.NewVarchar(1, 2, generator.ChoiceString([]string{"foo", "bar"}, nil)),
+			false,
+		},
+		{
+			"char",
+			CharType,
+			map[ArgName]interface{}{ChoiceArg: []interface{}{"a", "b"}},
+			generator.NewBPChar(1, 2, generator.ChoiceString([]string{"a", "b"}, nil)),
+			false,
+		},
+		{
+			"int2",
+			Int2Type,
+			map[ArgName]interface{}{ChoiceArg: []interface{}{1, 2, 3}},
+			generator.NewInt2Choice(1, 2, []int16{1, 2, 3}, nil),
+			false,
+		},
+		{
+			"int4 weighted",
+			Int4Type,
+			map[ArgName]interface{}{ChoiceArg: map[interface{}]interface{}{10: 1, 2: 3, -1: 0}},
+			generator.NewInt4Choice(1, 2, []int32{-1, 2, 10}, []float64{0, 3, 1}),
+			false,
+		},
+		{
+			"int8",
+			Int8Type,
+			map[ArgName]interface{}{ChoiceArg: []interface{}{int64(1) << 40, 2}},
+			generator.NewInt8Choice(1, 2, []int64{1 << 40, 2}, nil),
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Column{
+				Seed:            1,
+				NullProbability: 2,
+				Type:            tt.Type,
+				Generator:       tt.Generator,
+			}
+
+			err := func() (err error) {
+				defer func() { err, _ = recover().(error) }()
+				if got := c.valueGenerator(1000); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Column.valueGenerator() = %v, want %v", got, tt.want)
+				}
+				return nil
+			}()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("column.valueGenerator() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
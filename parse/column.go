@@ -22,7 +22,11 @@ package parse
 
 import (
 	"fmt"
+	"math"
+	"math/big"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/muhlemmer/pg_testdata/generator"
 )
@@ -30,8 +34,26 @@ import (
 type TypeName string
 
 const (
-	BoolType TypeName = "bool"
-	Int4Type TypeName = "int4"
+	BoolType    TypeName = "bool"
+	Int2Type    TypeName = "int2"
+	Int4Type    TypeName = "int4"
+	Int8Type    TypeName = "int8"
+	NumericType TypeName = "numeric"
+	Float4Type  TypeName = "float4"
+	Float8Type  TypeName = "float8"
+	TextType    TypeName = "text"
+	VarcharType TypeName = "varchar"
+	CharType    TypeName = "char"
+
+	DateType        TypeName = "date"
+	TimeType        TypeName = "time"
+	TimestampType   TypeName = "timestamp"
+	TimestamptzType TypeName = "timestamptz"
+	IntervalType    TypeName = "interval"
+
+	UUIDType      TypeName = "uuid"
+	EnumType      TypeName = "enum"
+	ReferenceType TypeName = "reference"
 )
 
 type ArgName string
@@ -40,8 +62,54 @@ const (
 	MinArg         ArgName = "min"
 	MaxArg         ArgName = "max"
 	ProbabilityArg ArgName = "probability"
+	PrecisionArg   ArgName = "precision"
+	ScaleArg       ArgName = "scale"
+	DistArg        ArgName = "distribution"
+	MeanArg        ArgName = "mean"
+	StdDevArg      ArgName = "stddev"
+	LengthArg      ArgName = "length"
+	MinLengthArg   ArgName = "min_length"
+	MaxLengthArg   ArgName = "max_length"
+	CharsetArg     ArgName = "charset"
+	RegexArg       ArgName = "regex"
+	MaxRepeatArg   ArgName = "max_repeat"
+	GranularityArg ArgName = "granularity"
+	ModeArg        ArgName = "mode"
+	StartArg       ArgName = "start"
+	StepArg        ArgName = "step"
+	JitterArg      ArgName = "jitter"
+	VersionArg     ArgName = "version"
+	ChoiceArg      ArgName = "choice"
+	TableArg       ArgName = "table"
+	ColumnArg      ArgName = "column"
+	SkewArg        ArgName = "skew"
+)
+
+// defaultMaxRepeat is the default for MaxRepeatArg.
+const defaultMaxRepeat = 10
+
+// Distribution names, accepted by DistArg.
+const (
+	UniformDist = "uniform"
+	NormalDist  = "normal"
 )
 
+// charsets accepted by CharsetArg.
+var charsets = map[string]generator.Charset{
+	"alphanumeric": generator.Alphanumeric,
+	"hex":          generator.Hex,
+	"printable":    generator.Printable,
+	"unicode":      generator.Unicode,
+}
+
+// maxCharLength is the maximum length that can be declared
+// for a varchar or char column.
+const maxCharLength = 10485760
+
+// maxNumericPrecision is the maximum precision that can be declared
+// for a numeric column.
+const maxNumericPrecision = 1000
+
 // Column information and parameters.
 type Column struct {
 	Name            string
@@ -49,6 +117,10 @@ type Column struct {
 	NullProbability float32
 	Type            TypeName
 	Generator       map[ArgName]interface{}
+	Unique          bool `yaml:",omitempty"` // Unique non-null values, for columns with a unique constraint.
+
+	referenced []generator.Encoded
+	offset     int // Rows generated by preceding chunks of the table.
 }
 
 type columnError struct {
@@ -98,17 +170,476 @@ func (c *Column) assertFloat32(v interface{}) float32 {
 	}
 }
 
+// assertInt64 returns the integer Generator argument arg.
+// Yaml decodes integers as int, int64 or uint64, depending on their size.
+func (c *Column) assertInt64(arg ArgName) int64 {
+	switch i := c.Generator[arg].(type) {
+	case int:
+		return int64(i)
+	case int64:
+		return i
+	case uint64:
+		if i <= math.MaxInt64 {
+			return int64(i)
+		}
+		c.panic(fmt.Errorf("%q value %d out of range", arg, i))
+		return 0
+	default:
+		c.panic(fmt.Errorf("%q incorrect type: %T, expected: int", arg, i))
+		return 0
+	}
+}
+
+// assertFloat64 returns the floating point Generator argument arg.
+func (c *Column) assertFloat64(arg ArgName) float64 {
+	switch f := c.Generator[arg].(type) {
+	case float64:
+		return f
+	case int:
+		return float64(f)
+	case int64:
+		return float64(f)
+	case uint64:
+		return float64(f)
+	default:
+		c.panic(fmt.Errorf("%q incorrect type: %T, expected: float64", arg, f))
+		return 0
+	}
+}
+
+// assertString returns the string Generator argument arg.
+func (c *Column) assertString(arg ArgName) string {
+	s, ok := c.Generator[arg].(string)
+	if !ok {
+		c.panic(fmt.Errorf("%q incorrect type: %T, expected: string", arg, c.Generator[arg]))
+	}
+
+	return s
+}
+
+// assertRat returns the decimal Generator argument arg.
+// Decimals may be passed as integer, float or string.
+// Strings allow for values which can't be represented by a float64.
+func (c *Column) assertRat(arg ArgName) *big.Rat {
+	var s string
+
+	switch v := c.Generator[arg].(type) {
+	case int:
+		s = strconv.Itoa(v)
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	case uint64:
+		s = strconv.FormatUint(v, 10)
+	case float64:
+		// Shortest representation, so that 0.1 stays 0.1.
+		s = strconv.FormatFloat(v, 'g', -1, 64)
+	case string:
+		s = v
+	default:
+		c.panic(fmt.Errorf("%q incorrect type: %T, expected: decimal", arg, v))
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		c.panic(fmt.Errorf("%q invalid decimal %q", arg, s))
+	}
+
+	return r
+}
+
+// intRange returns the min and max Generator arguments.
+// It panics if any of them is missing, if min is greater than max
+// or if any of them is outside the lower and upper bounds of the type.
+func (c *Column) intRange(tp TypeName, lower, upper int64) (min, max int64) {
+	c.requiredGenOpts(tp, MinArg, MaxArg)
+
+	min, max = c.assertInt64(MinArg), c.assertInt64(MaxArg)
+
+	for _, v := range []int64{min, max} {
+		if v < lower || v > upper {
+			c.panic(fmt.Errorf("value %d out of range for type %q", v, tp))
+		}
+	}
+	if min > max {
+		c.panic(fmt.Errorf("%q %d greater than %q %d", MinArg, min, MaxArg, max))
+	}
+
+	return min, max
+}
+
 func (c *Column) boolType() generator.Value {
 	c.requiredGenOpts(BoolType, ProbabilityArg)
 
 	return generator.NewBool(c.Seed, c.NullProbability, c.assertFloat32(c.Generator[ProbabilityArg]))
 }
 
+func (c *Column) int2Type() generator.Value {
+	min, max := c.intRange(Int2Type, math.MinInt16, math.MaxInt16)
+
+	return generator.NewInt2(c.Seed, c.NullProbability, int16(min), int16(max))
+}
+
+func (c *Column) int4Type() generator.Value {
+	min, max := c.intRange(Int4Type, math.MinInt32, math.MaxInt32)
+
+	return generator.NewInt4(c.Seed, c.NullProbability, int32(min), int32(max))
+}
+
+func (c *Column) int8Type() generator.Value {
+	min, max := c.intRange(Int8Type, math.MinInt64, math.MaxInt64)
+
+	return generator.NewInt8(c.Seed, c.NullProbability, min, max)
+}
+
+// numericScale returns the precision and scale Generator arguments.
+// Precision is optional and 0 if absent. Scale defaults to 0.
+func (c *Column) numericScale() (precision, scale int64) {
+	if _, ok := c.Generator[PrecisionArg]; ok {
+		precision = c.assertInt64(PrecisionArg)
+		if precision < 1 || precision > maxNumericPrecision {
+			c.panic(fmt.Errorf("%q %d out of range 1 to %d", PrecisionArg, precision, maxNumericPrecision))
+		}
+	}
+	if _, ok := c.Generator[ScaleArg]; ok {
+		scale = c.assertInt64(ScaleArg)
+		if scale < 0 || scale > maxNumericPrecision {
+			c.panic(fmt.Errorf("%q %d out of range 0 to %d", ScaleArg, scale, maxNumericPrecision))
+		}
+	}
+	if precision > 0 && scale > precision {
+		c.panic(fmt.Errorf("%q %d greater than %q %d", ScaleArg, scale, PrecisionArg, precision))
+	}
+
+	return precision, scale
+}
+
+// numericType panics if min or max is missing and precision is not set.
+// When precision is set, missing min or max default to the
+// lowest or highest value which fits into numeric(precision, scale).
+func (c *Column) numericType() generator.Value {
+	precision, scale := c.numericScale()
+	if precision == 0 {
+		c.requiredGenOpts(NumericType, MinArg, MaxArg)
+	}
+
+	ten := big.NewInt(10)
+	unit := new(big.Int).Exp(ten, big.NewInt(scale), nil)
+
+	var min, max, limit *big.Int
+
+	if precision > 0 {
+		// Exclusive limit of the unscaled absolute value.
+		limit = new(big.Int).Exp(ten, big.NewInt(precision), nil)
+		max = new(big.Int).Sub(limit, big.NewInt(1))
+		min = new(big.Int).Neg(max)
+	}
+
+	if _, ok := c.Generator[MinArg]; ok {
+		// Round up, to stay within min.
+		r := c.assertRat(MinArg)
+		r.Neg(r).Mul(r, new(big.Rat).SetInt(unit))
+		min = new(big.Int).Div(r.Num(), r.Denom())
+		min.Neg(min)
+	}
+	if _, ok := c.Generator[MaxArg]; ok {
+		// Round down, to stay within max.
+		r := c.assertRat(MaxArg)
+		r.Mul(r, new(big.Rat).SetInt(unit))
+		max = new(big.Int).Div(r.Num(), r.Denom())
+	}
+
+	if limit != nil {
+		for _, v := range []*big.Int{min, max} {
+			if new(big.Int).Abs(v).Cmp(limit) >= 0 {
+				c.panic(fmt.Errorf("value %s out of range for type %s(%d, %d)", new(big.Rat).SetFrac(v, unit).FloatString(int(scale)), NumericType, precision, scale))
+			}
+		}
+	}
+	if min.Cmp(max) > 0 {
+		c.panic(fmt.Errorf("%q greater than %q with scale %d", MinArg, MaxArg, scale))
+	}
+
+	return generator.NewNumeric(c.Seed, c.NullProbability, min, max, int32(scale))
+}
+
+// floatRange returns the min and max Generator arguments,
+// or -Inf and +Inf respectively when absent.
+// It panics if min is greater than max,
+// or if any of them is outside of [-limit, limit].
+func (c *Column) floatRange(tp TypeName, limit float64) (min, max float64) {
+	min, max = math.Inf(-1), math.Inf(1)
+
+	if _, ok := c.Generator[MinArg]; ok {
+		min = c.assertFloat64(MinArg)
+	}
+	if _, ok := c.Generator[MaxArg]; ok {
+		max = c.assertFloat64(MaxArg)
+	}
+
+	for _, v := range []float64{min, max} {
+		if !math.IsInf(v, 0) && math.Abs(v) > limit {
+			c.panic(fmt.Errorf("value %g out of range for type %q", v, tp))
+		}
+	}
+	if min > max {
+		c.panic(fmt.Errorf("%q %g greater than %q %g", MinArg, min, MaxArg, max))
+	}
+
+	return min, max
+}
+
+// floatDistribution returns the Distribution named by the optional DistArg.
+// The uniform distribution is the default and requires min and max.
+// The normal distribution requires mean and stddev and is clamped
+// by min and / or max if present.
+func (c *Column) floatDistribution(tp TypeName, limit float64) generator.Distribution {
+	dist := UniformDist
+	if _, ok := c.Generator[DistArg]; ok {
+		dist = c.assertString(DistArg)
+	}
+
+	switch dist {
+	case UniformDist:
+		c.requiredGenOpts(tp, MinArg, MaxArg)
+		return generator.Uniform(c.floatRange(tp, limit))
+
+	case NormalDist:
+		c.requiredGenOpts(tp, MeanArg, StdDevArg)
+
+		mean, stdDev := c.assertFloat64(MeanArg), c.assertFloat64(StdDevArg)
+		if stdDev < 0 {
+			c.panic(fmt.Errorf("negative %q %g", StdDevArg, stdDev))
+		}
+
+		min, max := c.floatRange(tp, limit)
+		return generator.ClampedNormal(mean, stdDev, min, max)
+
+	default:
+		c.panic(fmt.Errorf("unsupported %q %q for type %q", DistArg, dist, tp))
+		return nil
+	}
+}
+
+func (c *Column) float4Type() generator.Value {
+	return generator.NewFloat4(c.Seed, c.NullProbability, c.floatDistribution(Float4Type, math.MaxFloat32))
+}
+
+func (c *Column) float8Type() generator.Value {
+	return generator.NewFloat8(c.Seed, c.NullProbability, c.floatDistribution(Float8Type, math.MaxFloat64))
+}
+
+// typeLength returns the optional length type modifier,
+// as in varchar(length) or char(length).
+// A char column without length has a length of 1, as in PostgreSQL.
+// Zero is returned for an unlimited length.
+func (c *Column) typeLength(tp TypeName) int64 {
+	if _, ok := c.Generator[LengthArg]; !ok {
+		if tp == CharType {
+			return 1
+		}
+		return 0
+	}
+
+	length := c.assertInt64(LengthArg)
+	if length < 1 || length > maxCharLength {
+		c.panic(fmt.Errorf("%q %d out of range 1 to %d", LengthArg, length, maxCharLength))
+	}
+
+	return length
+}
+
+// regexSource returns a RegexString source from the Generator arguments.
+// MaxRepeatArg defaults to 10. When length is not 0,
+// the longest possible match may not exceed it.
+func (c *Column) regexSource(tp TypeName, length int64) generator.StringSource {
+	for _, arg := range []ArgName{MinLengthArg, MaxLengthArg, CharsetArg} {
+		if _, ok := c.Generator[arg]; ok {
+			c.panic(fmt.Errorf("%q can not be combined with %q for type %q", arg, RegexArg, tp))
+		}
+	}
+
+	maxRepeat := int64(defaultMaxRepeat)
+	if _, ok := c.Generator[MaxRepeatArg]; ok {
+		if maxRepeat = c.assertInt64(MaxRepeatArg); maxRepeat < 0 {
+			c.panic(fmt.Errorf("negative %q %d", MaxRepeatArg, maxRepeat))
+		}
+	}
+
+	src, err := generator.RegexString(c.assertString(RegexArg), int(maxRepeat))
+	if err != nil {
+		c.panic(err)
+	}
+
+	if n := generator.MaxLength(src); length > 0 && int64(n) > length {
+		c.panic(fmt.Errorf("%q may generate %d characters, more than %q %d", RegexArg, n, LengthArg, length))
+	}
+
+	return src
+}
+
+// stringSource returns a StringSource from the Generator arguments.
+// If RegexArg is present, a RegexString source is returned.
+// Otherwise a RandomString, for which MaxLengthArg is required, unless length is not 0.
+// In which case max_length defaults to length and may not exceed it.
+// MinLengthArg defaults to 0 and CharsetArg to "alphanumeric".
+func (c *Column) stringSource(tp TypeName, length int64) generator.StringSource {
+	if _, ok := c.Generator[RegexArg]; ok {
+		return c.regexSource(tp, length)
+	}
+
+	var minLen, maxLen int64
+
+	if _, ok := c.Generator[MaxLengthArg]; ok || length == 0 {
+		c.requiredGenOpts(tp, MaxLengthArg)
+		maxLen = c.assertInt64(MaxLengthArg)
+	} else {
+		maxLen = length
+	}
+	if _, ok := c.Generator[MinLengthArg]; ok {
+		minLen = c.assertInt64(MinLengthArg)
+	}
+
+	if minLen < 0 {
+		c.panic(fmt.Errorf("negative %q %d", MinLengthArg, minLen))
+	}
+	if minLen > maxLen {
+		c.panic(fmt.Errorf("%q %d greater than %q %d", MinLengthArg, minLen, MaxLengthArg, maxLen))
+	}
+	if length > 0 && maxLen > length {
+		c.panic(fmt.Errorf("%q %d greater than %q %d", MaxLengthArg, maxLen, LengthArg, length))
+	}
+
+	charset := generator.Alphanumeric
+	if _, ok := c.Generator[CharsetArg]; ok {
+		name := c.assertString(CharsetArg)
+		if charset, ok = charsets[name]; !ok {
+			c.panic(fmt.Errorf("unsupported %q %q", CharsetArg, name))
+		}
+	}
+
+	return generator.RandomString(int(minLen), int(maxLen), charset)
+}
+
+func (c *Column) textType() generator.Value {
+	return generator.NewText(c.Seed, c.NullProbability, c.stringSource(TextType, 0))
+}
+
+func (c *Column) varcharType() generator.Value {
+	return generator.NewVarchar(c.Seed, c.NullProbability, c.stringSource(VarcharType, c.typeLength(VarcharType)))
+}
+
+func (c *Column) charType() generator.Value {
+	return generator.NewBPChar(c.Seed, c.NullProbability, c.stringSource(CharType, c.typeLength(CharType)))
+}
+
+// uuidType supports version 4 (random), which is the default,
+// and version 7 (time-ordered), which requires a min and max timestamp.
+func (c *Column) uuidType() generator.Value {
+	version := int64(4)
+	if _, ok := c.Generator[VersionArg]; ok {
+		version = c.assertInt64(VersionArg)
+	}
+
+	switch version {
+	case 4:
+		return generator.NewUUIDv4(c.Seed, c.NullProbability)
+	case 7:
+		min, max := c.timeRange(UUIDType, time.Millisecond)
+		if min.Before(time.Unix(0, 0)) || max.After(generator.MaxUUIDv7Time) {
+			c.panic(fmt.Errorf("timestamps out of range for %q version 7", UUIDType))
+		}
+
+		return generator.NewUUIDv7(c.Seed, c.NullProbability, min, max)
+	default:
+		c.panic(fmt.Errorf("unsupported %q %d for type %q", VersionArg, version, UUIDType))
+		return nil
+	}
+}
+
 // valueGenerator panics in case of an invalid Type argument.
-func (c *Column) valueGenerator() generator.Value {
+// Amount is the number of values that will be generated,
+// used to validate the range of sequences.
+// If the column is Unique, amount may not exceed the amount
+// of distinct values the generator can produce.
+// Unique sequences may not have a jitter, as retrying a value would
+// advance the sequence. Without jitter, they are unique already.
+func (c *Column) valueGenerator(amount int) generator.Value {
+	vg := c.typeGenerator(amount)
+	if !c.Unique {
+		return vg
+	}
+
+	if n := generator.Cardinality(vg); n != nil && n.Cmp(big.NewInt(int64(amount))) < 0 {
+		c.panic(fmt.Errorf("amount %d exceeds %s distinct values of unique column", amount, n))
+	}
+
+	if c.mode() == SequenceMode {
+		if _, ok := c.Generator[JitterArg]; ok {
+			c.panic(fmt.Errorf("%q can not be combined with unique %q %q", JitterArg, ModeArg, SequenceMode))
+		}
+		return vg
+	}
+
+	return generator.NewUnique(vg)
+}
+
+// validate recovers the error of valueGenerator.
+func (c *Column) validate(amount int) (err error) {
+	defer func() {
+		err, _ = recover().(error)
+	}()
+
+	c.valueGenerator(amount)
+	return nil
+}
+
+// typeGenerator returns the value generator for Type.
+func (c *Column) typeGenerator(amount int) generator.Value {
+	if _, ok := c.Generator[ChoiceArg]; ok {
+		return c.choiceGenerator()
+	}
+	if c.mode() == SequenceMode {
+		return c.sequenceGenerator(amount)
+	}
+
 	switch c.Type {
 	case BoolType:
 		return c.boolType()
+	case Int2Type:
+		return c.int2Type()
+	case Int4Type:
+		return c.int4Type()
+	case Int8Type:
+		return c.int8Type()
+	case NumericType:
+		return c.numericType()
+	case Float4Type:
+		return c.float4Type()
+	case Float8Type:
+		return c.float8Type()
+	case TextType:
+		return c.textType()
+	case VarcharType:
+		return c.varcharType()
+	case CharType:
+		return c.charType()
+	case DateType:
+		return c.dateType()
+	case TimeType:
+		return c.timeType()
+	case TimestampType:
+		return c.timestampType()
+	case TimestamptzType:
+		return c.timestamptzType()
+	case IntervalType:
+		return c.intervalType()
+	case UUIDType:
+		return c.uuidType()
+	case EnumType:
+		c.requiredGenOpts(EnumType, ChoiceArg)
+		return nil
+	case ReferenceType:
+		return c.referenceType()
 	default:
 		c.panic(fmt.Errorf("unsuported type %q", c.Type))
 		return nil
@@ -22,8 +22,11 @@ package parse
 
 import (
 	"fmt"
+	"math"
+	"math/big"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/muhlemmer/pg_testdata/generator"
 )
@@ -143,6 +146,150 @@ func Test_assertFloat32(t *testing.T) {
 	}
 }
 
+func Test_column_assertInt64(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       interface{}
+		want    int64
+		wantErr bool
+	}{
+		{
+			"int",
+			1,
+			1,
+			false,
+		},
+		{
+			"int64",
+			int64(1),
+			1,
+			false,
+		},
+		{
+			"uint64",
+			uint64(1),
+			1,
+			false,
+		},
+		{
+			"uint64 overflow",
+			uint64(math.MaxUint64),
+			0,
+			true,
+		},
+		{
+			"float",
+			1.5,
+			0,
+			true,
+		},
+		{
+			"string",
+			"foo",
+			0,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Column{
+				Name:      "test",
+				Generator: map[ArgName]interface{}{MinArg: tt.v},
+			}
+
+			err := func() (err error) {
+				defer func() { err, _ = recover().(error) }()
+
+				if got := c.assertInt64(MinArg); got != tt.want {
+					t.Errorf("column.assertInt64() = %v, want %v", got, tt.want)
+				}
+
+				return
+			}()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("column.assertInt64() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
+
+func Test_column_assertRat(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       interface{}
+		want    string
+		wantErr bool
+	}{
+		{
+			"int",
+			1,
+			"1/1",
+			false,
+		},
+		{
+			"int64",
+			int64(-1),
+			"-1/1",
+			false,
+		},
+		{
+			"uint64",
+			uint64(math.MaxUint64),
+			"18446744073709551615/1",
+			false,
+		},
+		{
+			"float",
+			0.1,
+			"1/10",
+			false,
+		},
+		{
+			"string",
+			"123456789012345678901234567890.5",
+			"246913578024691357802469135781/2",
+			false,
+		},
+		{
+			"invalid string",
+			"foo",
+			"",
+			true,
+		},
+		{
+			"bool",
+			true,
+			"",
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Column{
+				Name:      "test",
+				Generator: map[ArgName]interface{}{MinArg: tt.v},
+			}
+
+			err := func() (err error) {
+				defer func() { err, _ = recover().(error) }()
+
+				if got := c.assertRat(MinArg).String(); got != tt.want {
+					t.Errorf("column.assertRat() = %v, want %v", got, tt.want)
+				}
+
+				return
+			}()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("column.assertRat() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
+
 func Test_column_boolType(t *testing.T) {
 	type fields struct {
 		Seed            int64
@@ -210,62 +357,743 @@ func Test_column_boolType(t *testing.T) {
 	}
 }
 
-const unsupportedType TypeName = "unsupported"
+func Test_column_int4Type(t *testing.T) {
+	tests := []struct {
+		name      string
+		Generator map[ArgName]interface{}
+		want      generator.Value
+		wantErr   bool
+	}{
+		{
+			"Missing arg",
+			map[ArgName]interface{}{MinArg: 1},
+			nil,
+			true,
+		},
+		{
+			"Wrong type",
+			map[ArgName]interface{}{MinArg: 1, MaxArg: "foo"},
+			nil,
+			true,
+		},
+		{
+			"Out of range",
+			map[ArgName]interface{}{MinArg: 1, MaxArg: math.MaxInt32 + 1},
+			nil,
+			true,
+		},
+		{
+			"Min greater than max",
+			map[ArgName]interface{}{MinArg: 10, MaxArg: 1},
+			nil,
+			true,
+		},
+		{
+			"OK",
+			map[ArgName]interface{}{MinArg: -10, MaxArg: 10},
+			generator.NewInt4(1, 2, -10, 10),
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Column{
+				Seed:            1,
+				NullProbability: 2,
+				Generator:       tt.Generator,
+			}
 
-func Test_column_valueGenerator(t *testing.T) {
-	col := Column{
-		Name:            "test_column",
-		Seed:            1,
-		NullProbability: 2,
+			err := func() (err error) {
+				defer func() { err, _ = recover().(error) }()
+				if got := c.int4Type(); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Column.int4Type() = %v, want %v", got, tt.want)
+				}
+				return nil
+			}()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("column.int4Type() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
 	}
+}
 
-	type fields struct {
+func Test_column_intTypes(t *testing.T) {
+	tests := []struct {
+		name      string
 		Type      TypeName
 		Generator map[ArgName]interface{}
+		want      generator.Value
+		wantErr   bool
+	}{
+		{
+			"int2 out of range",
+			Int2Type,
+			map[ArgName]interface{}{MinArg: 1, MaxArg: 70000},
+			nil,
+			true,
+		},
+		{
+			"int2 OK",
+			Int2Type,
+			map[ArgName]interface{}{MinArg: math.MinInt16, MaxArg: math.MaxInt16},
+			generator.NewInt2(1, 2, math.MinInt16, math.MaxInt16),
+			false,
+		},
+		{
+			"int8 out of range",
+			Int8Type,
+			map[ArgName]interface{}{MinArg: 1, MaxArg: uint64(math.MaxInt64 + 1)},
+			nil,
+			true,
+		},
+		{
+			"int8 OK",
+			Int8Type,
+			map[ArgName]interface{}{MinArg: math.MinInt64, MaxArg: math.MaxInt64},
+			generator.NewInt8(1, 2, math.MinInt64, math.MaxInt64),
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Column{
+				Seed:            1,
+				NullProbability: 2,
+				Type:            tt.Type,
+				Generator:       tt.Generator,
+			}
+
+			err := func() (err error) {
+				defer func() { err, _ = recover().(error) }()
+				if got := c.valueGenerator(1000); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Column.valueGenerator() = %v, want %v", got, tt.want)
+				}
+				return nil
+			}()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("column.valueGenerator() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
 	}
+}
+
+func Test_column_numericType(t *testing.T) {
 	tests := []struct {
-		name    string
-		fields  fields
-		wantVg  generator.Value
-		wantErr bool
+		name      string
+		Generator map[ArgName]interface{}
+		want      generator.Value
+		wantErr   bool
 	}{
 		{
-			"unsupported type",
-			fields{
-				Type:      unsupportedType,
-				Generator: nil,
-			},
+			"Missing arg",
+			map[ArgName]interface{}{MinArg: 1},
 			nil,
 			true,
 		},
 		{
-			"bool type",
-			fields{
-				Type:      BoolType,
-				Generator: map[ArgName]interface{}{ProbabilityArg: float32(50)},
-			},
-			generator.NewBool(1, 2, 50),
+			"Invalid precision",
+			map[ArgName]interface{}{PrecisionArg: 0},
+			nil,
+			true,
+		},
+		{
+			"Negative scale",
+			map[ArgName]interface{}{MinArg: 1, MaxArg: 2, ScaleArg: -1},
+			nil,
+			true,
+		},
+		{
+			"Scale greater than precision",
+			map[ArgName]interface{}{PrecisionArg: 2, ScaleArg: 3},
+			nil,
+			true,
+		},
+		{
+			"Out of precision",
+			map[ArgName]interface{}{PrecisionArg: 5, ScaleArg: 2, MaxArg: 1000},
+			nil,
+			true,
+		},
+		{
+			"Min greater than max",
+			map[ArgName]interface{}{MinArg: 1.55, MaxArg: 1.58, ScaleArg: 1},
+			nil,
+			true,
+		},
+		{
+			"Precision only",
+			map[ArgName]interface{}{PrecisionArg: 5, ScaleArg: 2},
+			generator.NewNumeric(1, 2, big.NewInt(-99999), big.NewInt(99999), 2),
+			false,
+		},
+		{
+			"Rounded bounds",
+			map[ArgName]interface{}{MinArg: -1.555, MaxArg: "2.555", ScaleArg: 2},
+			generator.NewNumeric(1, 2, big.NewInt(-155), big.NewInt(255), 2),
+			false,
+		},
+		{
+			"Precision with bounds",
+			map[ArgName]interface{}{PrecisionArg: 10, MinArg: 0, MaxArg: 100},
+			generator.NewNumeric(1, 2, big.NewInt(0), big.NewInt(100), 0),
 			false,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			c := &Column{
+				Seed:            1,
+				NullProbability: 2,
+				Generator:       tt.Generator,
+			}
+
 			err := func() (err error) {
 				defer func() { err, _ = recover().(error) }()
-
-				c := &Column{
-					Name:            col.Name,
-					Seed:            col.Seed,
-					NullProbability: col.NullProbability,
-					Type:            tt.fields.Type,
-					Generator:       tt.fields.Generator,
-				}
-				gotVg := c.valueGenerator()
-				if !reflect.DeepEqual(gotVg, tt.wantVg) {
-					t.Errorf("column.valueGenerator() = %v, want %v", gotVg, tt.wantVg)
+				if got := c.numericType(); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Column.numericType() = %v, want %v", got, tt.want)
 				}
+				return nil
+			}()
 
+			if (err != nil) != tt.wantErr {
+				t.Errorf("column.numericType() error = %v, wantErr %v", err, tt.wantErr)
 				return
+			}
+		})
+	}
+}
+
+func Test_column_floatTypes(t *testing.T) {
+	tests := []struct {
+		name      string
+		Type      TypeName
+		Generator map[ArgName]interface{}
+		want      generator.Value
+		wantErr   bool
+	}{
+		{
+			"uniform missing arg",
+			Float8Type,
+			map[ArgName]interface{}{MinArg: 1},
+			nil,
+			true,
+		},
+		{
+			"uniform wrong type",
+			Float8Type,
+			map[ArgName]interface{}{MinArg: 1, MaxArg: "foo"},
+			nil,
+			true,
+		},
+		{
+			"uniform min greater than max",
+			Float8Type,
+			map[ArgName]interface{}{MinArg: 1.5, MaxArg: 1},
+			nil,
+			true,
+		},
+		{
+			"float4 out of range",
+			Float4Type,
+			map[ArgName]interface{}{MinArg: 0, MaxArg: 1e39},
+			nil,
+			true,
+		},
+		{
+			"unsupported distribution",
+			Float8Type,
+			map[ArgName]interface{}{DistArg: "foo"},
+			nil,
+			true,
+		},
+		{
+			"normal missing arg",
+			Float8Type,
+			map[ArgName]interface{}{DistArg: NormalDist, MeanArg: 1},
+			nil,
+			true,
+		},
+		{
+			"normal negative stddev",
+			Float8Type,
+			map[ArgName]interface{}{DistArg: NormalDist, MeanArg: 1, StdDevArg: -1},
+			nil,
+			true,
+		},
+		{
+			"float4 uniform",
+			Float4Type,
+			map[ArgName]interface{}{MinArg: -1, MaxArg: 1.5},
+			generator.NewFloat4(1, 2, generator.Uniform(-1, 1.5)),
+			false,
+		},
+		{
+			"float8 uniform",
+			Float8Type,
+			map[ArgName]interface{}{DistArg: UniformDist, MinArg: -1, MaxArg: 1.5},
+			generator.NewFloat8(1, 2, generator.Uniform(-1, 1.5)),
+			false,
+		},
+		{
+			"float8 normal",
+			Float8Type,
+			map[ArgName]interface{}{DistArg: NormalDist, MeanArg: 10, StdDevArg: 2.5},
+			generator.NewFloat8(1, 2, generator.Normal(10, 2.5)),
+			false,
+		},
+		{
+			"float8 clamped normal",
+			Float8Type,
+			map[ArgName]interface{}{DistArg: NormalDist, MeanArg: 10, StdDevArg: 2.5, MinArg: 0},
+			generator.NewFloat8(1, 2, generator.ClampedNormal(10, 2.5, 0, math.Inf(1))),
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Column{
+				Seed:            1,
+				NullProbability: 2,
+				Type:            tt.Type,
+				Generator:       tt.Generator,
+			}
+
+			err := func() (err error) {
+				defer func() { err, _ = recover().(error) }()
+				if got := c.valueGenerator(1000); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Column.valueGenerator() = %v, want %v", got, tt.want)
+				}
+				return nil
+			}()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("column.valueGenerator() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
+
+func mustRegexString(pattern string, maxRepeat int) generator.StringSource {
+	src, err := generator.RegexString(pattern, maxRepeat)
+	if err != nil {
+		panic(err)
+	}
+	return src
+}
+
+func Test_column_textTypes(t *testing.T) {
+	tests := []struct {
+		name      string
+		Type      TypeName
+		Generator map[ArgName]interface{}
+		want      generator.Value
+		wantErr   bool
+	}{
+		{
+			"text missing arg",
+			TextType,
+			map[ArgName]interface{}{MinLengthArg: 1},
+			nil,
+			true,
+		},
+		{
+			"text negative min_length",
+			TextType,
+			map[ArgName]interface{}{MinLengthArg: -1, MaxLengthArg: 10},
+			nil,
+			true,
+		},
+		{
+			"text min_length greater than max_length",
+			TextType,
+			map[ArgName]interface{}{MinLengthArg: 11, MaxLengthArg: 10},
+			nil,
+			true,
+		},
+		{
+			"text unsupported charset",
+			TextType,
+			map[ArgName]interface{}{MaxLengthArg: 10, CharsetArg: "foo"},
+			nil,
+			true,
+		},
+		{
+			"text",
+			TextType,
+			map[ArgName]interface{}{MinLengthArg: 1, MaxLengthArg: 10, CharsetArg: "unicode"},
+			generator.NewText(1, 2, generator.RandomString(1, 10, generator.Unicode)),
+			false,
+		},
+		{
+			"varchar invalid length",
+			VarcharType,
+			map[ArgName]interface{}{LengthArg: 0},
+			nil,
+			true,
+		},
+		{
+			"varchar max_length greater than length",
+			VarcharType,
+			map[ArgName]interface{}{LengthArg: 5, MaxLengthArg: 6},
+			nil,
+			true,
+		},
+		{
+			"varchar length",
+			VarcharType,
+			map[ArgName]interface{}{LengthArg: 5, CharsetArg: "hex"},
+			generator.NewVarchar(1, 2, generator.RandomString(0, 5, generator.Hex)),
+			false,
+		},
+		{
+			"varchar unlimited",
+			VarcharType,
+			map[ArgName]interface{}{MaxLengthArg: 500},
+			generator.NewVarchar(1, 2, generator.RandomString(0, 500, generator.Alphanumeric)),
+			false,
+		},
+		{
+			"char default length",
+			CharType,
+			map[ArgName]interface{}{CharsetArg: "printable"},
+			generator.NewBPChar(1, 2, generator.RandomString(0, 1, generator.Printable)),
+			false,
+		},
+		{
+			"char max_length greater than default length",
+			CharType,
+			map[ArgName]interface{}{MaxLengthArg: 2},
+			nil,
+			true,
+		},
+		{
+			"regex invalid",
+			TextType,
+			map[ArgName]interface{}{RegexArg: "["},
+			nil,
+			true,
+		},
+		{
+			"regex wrong type",
+			TextType,
+			map[ArgName]interface{}{RegexArg: 1},
+			nil,
+			true,
+		},
+		{
+			"regex with charset",
+			TextType,
+			map[ArgName]interface{}{RegexArg: "[a-z]+", CharsetArg: "hex"},
+			nil,
+			true,
+		},
+		{
+			"regex negative max_repeat",
+			TextType,
+			map[ArgName]interface{}{RegexArg: "[a-z]+", MaxRepeatArg: -1},
+			nil,
+			true,
+		},
+		{
+			"regex exceeds length",
+			VarcharType,
+			map[ArgName]interface{}{RegexArg: "[a-z]+", LengthArg: 5},
+			nil,
+			true,
+		},
+		{
+			"regex text",
+			TextType,
+			map[ArgName]interface{}{RegexArg: "[a-z]+"},
+			generator.NewText(1, 2, mustRegexString("[a-z]+", defaultMaxRepeat)),
+			false,
+		},
+		{
+			"regex varchar",
+			VarcharType,
+			map[ArgName]interface{}{RegexArg: "[a-z]+", MaxRepeatArg: 5, LengthArg: 5},
+			generator.NewVarchar(1, 2, mustRegexString("[a-z]+", 5)),
+			false,
+		},
+		{
+			"regex char",
+			CharType,
+			map[ArgName]interface{}{RegexArg: "[A-Z]{3}-[0-9]{6}", LengthArg: 10},
+			generator.NewBPChar(1, 2, mustRegexString("[A-Z]{3}-[0-9]{6}", defaultMaxRepeat)),
+			false,
+		},
+		{
+			"char length",
+			CharType,
+			map[ArgName]interface{}{LengthArg: 3, MinLengthArg: 3},
+			generator.NewBPChar(1, 2, generator.RandomString(3, 3, generator.Alphanumeric)),
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Column{
+				Seed:            1,
+				NullProbability: 2,
+				Type:            tt.Type,
+				Generator:       tt.Generator,
+			}
+
+			err := func() (err error) {
+				defer func() { err, _ = recover().(error) }()
+				if got := c.valueGenerator(1000); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Column.valueGenerator() = %v, want %v", got, tt.want)
+				}
+				return nil
+			}()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("column.valueGenerator() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
+
+func Test_column_uuidType(t *testing.T) {
+	tests := []struct {
+		name      string
+		Generator map[ArgName]interface{}
+		want      generator.Value
+		wantErr   bool
+	}{
+		{
+			"default version",
+			nil,
+			generator.NewUUIDv4(1, 2),
+			false,
+		},
+		{
+			"unsupported version",
+			map[ArgName]interface{}{VersionArg: 1},
+			nil,
+			true,
+		},
+		{
+			"v7 missing arg",
+			map[ArgName]interface{}{VersionArg: 7, MinArg: "2021-01-01"},
+			nil,
+			true,
+		},
+		{
+			"v7 before epoch",
+			map[ArgName]interface{}{VersionArg: 7, MinArg: "1969-12-31", MaxArg: "2021-01-01"},
+			nil,
+			true,
+		},
+		{
+			"v4",
+			map[ArgName]interface{}{VersionArg: 4},
+			generator.NewUUIDv4(1, 2),
+			false,
+		},
+		{
+			"v7",
+			map[ArgName]interface{}{VersionArg: 7, MinArg: "2021-01-01", MaxArg: "2021-12-31"},
+			generator.NewUUIDv7(1, 2, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 12, 31, 0, 0, 0, 0, time.UTC)),
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Column{
+				Seed:            1,
+				NullProbability: 2,
+				Type:            UUIDType,
+				Generator:       tt.Generator,
+			}
+
+			err := func() (err error) {
+				defer func() { err, _ = recover().(error) }()
+				if got := c.valueGenerator(1000); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Column.valueGenerator() = %v, want %v", got, tt.want)
+				}
+				return nil
+			}()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("column.valueGenerator() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
+
+const unsupportedType TypeName = "unsupported"
+
+func Test_column_valueGenerator(t *testing.T) {
+	col := Column{
+		Name:            "test_column",
+		Seed:            1,
+		NullProbability: 2,
+	}
+
+	type fields struct {
+		Type      TypeName
+		Generator map[ArgName]interface{}
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		wantVg  generator.Value
+		wantErr bool
+	}{
+		{
+			"unsupported type",
+			fields{
+				Type:      unsupportedType,
+				Generator: nil,
+			},
+			nil,
+			true,
+		},
+		{
+			"bool type",
+			fields{
+				Type:      BoolType,
+				Generator: map[ArgName]interface{}{ProbabilityArg: float32(50)},
+			},
+			generator.NewBool(1, 2, 50),
+			false,
+		},
+		{
+			"int4 type",
+			fields{
+				Type:      Int4Type,
+				Generator: map[ArgName]interface{}{MinArg: 1, MaxArg: 100},
+			},
+			generator.NewInt4(1, 2, 1, 100),
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := func() (err error) {
+				defer func() { err, _ = recover().(error) }()
+
+				c := &Column{
+					Name:            col.Name,
+					Seed:            col.Seed,
+					NullProbability: col.NullProbability,
+					Type:            tt.fields.Type,
+					Generator:       tt.fields.Generator,
+				}
+				gotVg := c.valueGenerator(1000)
+				if !reflect.DeepEqual(gotVg, tt.wantVg) {
+					t.Errorf("column.valueGenerator() = %v, want %v", gotVg, tt.wantVg)
+				}
+
+				return
+			}()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("column.valueGenerator() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
+
+func Test_column_valueGenerator_unique(t *testing.T) {
+	tests := []struct {
+		name      string
+		Type      TypeName
+		Generator map[ArgName]interface{}
+		amount    int
+		want      generator.Value
+		wantErr   bool
+	}{
+		{
+			"int2 range exceeded",
+			Int2Type,
+			map[ArgName]interface{}{MinArg: 1, MaxArg: 10},
+			11,
+			nil,
+			true,
+		},
+		{
+			"choice exceeded",
+			TextType,
+			map[ArgName]interface{}{ChoiceArg: []interface{}{"foo", "bar", "foo"}},
+			3,
+			nil,
+			true,
+		},
+		{
+			"ambiguous regex exceeded",
+			TextType,
+			map[ArgName]interface{}{RegexArg: "a?a?"},
+			4,
+			nil,
+			true,
+		},
+		{
+			"int2",
+			Int2Type,
+			map[ArgName]interface{}{MinArg: 1, MaxArg: 10},
+			10,
+			generator.NewUnique(generator.NewInt2(1, 2, 1, 10)),
+			false,
+		},
+		{
+			"uuid",
+			UUIDType,
+			nil,
+			1000000,
+			generator.NewUnique(generator.NewUUIDv4(1, 2)),
+			false,
+		},
+		{
+			"sequence",
+			Int4Type,
+			map[ArgName]interface{}{ModeArg: SequenceMode, StartArg: 1},
+			10,
+			generator.NewInt4Sequence(1, 2, 1, 1, 0),
+			false,
+		},
+		{
+			"sequence with jitter",
+			Int4Type,
+			map[ArgName]interface{}{ModeArg: SequenceMode, StartArg: 1, JitterArg: 1},
+			10,
+			nil,
+			true,
+		},
+		{
+			"sequence without step",
+			Int4Type,
+			map[ArgName]interface{}{ModeArg: SequenceMode, StepArg: 0},
+			2,
+			nil,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Column{
+				Seed:            1,
+				NullProbability: 2,
+				Type:            tt.Type,
+				Generator:       tt.Generator,
+				Unique:          true,
+			}
+
+			err := func() (err error) {
+				defer func() { err, _ = recover().(error) }()
+				if got := c.valueGenerator(tt.amount); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Column.valueGenerator() = %v, want %v", got, tt.want)
+				}
+				return nil
 			}()
 
 			if (err != nil) != tt.wantErr {
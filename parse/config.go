@@ -18,27 +18,35 @@ You should have received a copy of the GNU Affero General Public License
 along with this program.  If not, see <https://www.gnu.org/licenses/>.
 */
 
-// Package parse loads yaml from a configuration file, parses its arguments
+// Package parse loads yaml from a configuration file, reader or file system, parses its arguments
 // and initializes queries with type specific generator arguments.
 package parse
 
 import (
 	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
 
 	"gopkg.in/yaml.v2"
 )
 
 // Config structure root, meant to be marshalled / unmarshalled with yaml.
 type Config struct {
-	DSN    string // Data Source Name, aka connection string.
-	Tables []*Table
+	DSN         string      // Data Source Name, aka connection string.
+	Transaction Transaction `yaml:",omitempty"`             // Default Transaction mode of the tables, or AllTransaction for the whole run.
+	CommitEvery int         `yaml:"commit_every,omitempty"` // Default rows per transaction, for BatchTransaction.
+	Tables      []*Table
 }
 
-// Load a yaml config file.
-func Load(filename string) (*Config, error) {
-	buf, err := yamlTemplate(nil, filename)
+// decode the yaml config in text, after executing it as a template,
+// which provides the env function to all loaders.
+func decode(name string, text []byte) (*Config, error) {
+	buf, err := yamlTemplate(nil, name, text)
 	if err != nil {
-		return nil, fmt.Errorf("parse.Load: %w", err)
+		return nil, err
 	}
 
 	dec := yaml.NewDecoder(buf)
@@ -46,8 +54,63 @@ func Load(filename string) (*Config, error) {
 	conf := new(Config)
 
 	if err = dec.Decode(conf); err != nil {
+		return nil, err
+	}
+
+	return conf, nil
+}
+
+// Load a yaml config file.
+func Load(filename string) (*Config, error) {
+	text, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("parse.Load: %w", err)
+	}
+
+	conf, err := decode(filepath.Base(filename), text)
+	if err != nil {
 		return nil, fmt.Errorf("parse.Load: %w", err)
 	}
 
 	return conf, nil
 }
+
+// LoadFS loads a yaml config file from fsys, such as an embed.FS.
+func LoadFS(fsys fs.FS, name string) (*Config, error) {
+	text, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("parse.LoadFS: %w", err)
+	}
+
+	conf, err := decode(path.Base(name), text)
+	if err != nil {
+		return nil, fmt.Errorf("parse.LoadFS: %w", err)
+	}
+
+	return conf, nil
+}
+
+// LoadReader loads a yaml config from r, until EOF.
+func LoadReader(r io.Reader) (*Config, error) {
+	text, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("parse.LoadReader: %w", err)
+	}
+
+	conf, err := decode("config", text)
+	if err != nil {
+		return nil, fmt.Errorf("parse.LoadReader: %w", err)
+	}
+
+	return conf, nil
+}
+
+// Parse a yaml config.
+func Parse(text []byte) (*Config, error) {
+	conf, err := decode("config", text)
+	if err != nil {
+		return nil, fmt.Errorf("parse.Parse: %w", err)
+	}
+
+	return conf, nil
+}
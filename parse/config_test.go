@@ -21,10 +21,15 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 package parse
 
 import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
 	"log"
 	"os"
 	"reflect"
 	"testing"
+	"testing/fstest"
 )
 
 func TestMain(m *testing.M) {
@@ -74,3 +79,148 @@ func TestLoad(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"conf/invalid.yml": &fstest.MapFile{Data: []byte("foo: [")},
+	}
+
+	tests := []struct {
+		name    string
+		fsys    fs.FS
+		file    string
+		want    *Config
+		wantErr bool
+	}{
+		{
+			"File not found",
+			fsys,
+			"conf/does_not_exist.yml",
+			nil,
+			true,
+		},
+		{
+			"Invalid file",
+			fsys,
+			"conf/invalid.yml",
+			nil,
+			true,
+		},
+		{
+			"Example config",
+			os.DirFS("../testdata"),
+			"all_supported.yml",
+			&testConf,
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadFS(tt.fsys, tt.file)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadFS() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadFS() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("read error")
+}
+
+func TestLoadReader(t *testing.T) {
+	example, err := os.ReadFile("../testdata/all_supported.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		r       io.Reader
+		want    *Config
+		wantErr bool
+	}{
+		{
+			"Read error",
+			errReader{},
+			nil,
+			true,
+		},
+		{
+			"Template error",
+			bytes.NewBufferString("{{ foo }}"),
+			nil,
+			true,
+		},
+		{
+			"Example config",
+			bytes.NewReader(example),
+			&testConf,
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadReader(tt.r)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadReader() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadReader() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	if err := os.Setenv("TEST_PARSE_DBNAME", "foo"); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Unsetenv("TEST_PARSE_DBNAME")
+
+	tests := []struct {
+		name    string
+		text    string
+		want    *Config
+		wantErr bool
+	}{
+		{
+			"Invalid yaml",
+			"tables: [",
+			nil,
+			true,
+		},
+		{
+			"Env template",
+			`dsn: dbname={{ env "TEST_PARSE_DBNAME" "testdata" }}
+tables:
+- name: unit_tests
+  amount: 10
+`,
+			&Config{
+				DSN:    "dbname=foo",
+				Tables: []*Table{{Name: "unit_tests", Amount: 10}},
+			},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.text))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
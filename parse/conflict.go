@@ -0,0 +1,136 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ConflictAction is the action taken for rows which conflict with existing rows.
+type ConflictAction string
+
+const (
+	ConflictNothing ConflictAction = "nothing" // Skip conflicting rows.
+	ConflictUpdate  ConflictAction = "update"  // Update the existing rows with the values of conflicting rows.
+)
+
+// OnConflict handling of inserted rows, which violate a unique constraint.
+// ConflictUpdate with a BatchSize above 1 requires a Unique column in the conflict target.
+type OnConflict struct {
+	Action  ConflictAction
+	Columns []string `yaml:",omitempty"` // Conflict target, required for ConflictUpdate.
+	Update  []string `yaml:",omitempty"` // Columns to update, defaults to all columns not in the conflict target.
+}
+
+// Conflict returns the on conflict clause of the insert statement,
+// or an empty string if OnConflict is not set.
+// An error is returned if OnConflict is invalid or used with CopyMethod,
+// as COPY does not support conflict handling.
+func (table *Table) Conflict() (string, error) {
+	oc := table.OnConflict
+	if oc == nil {
+		return "", nil
+	}
+
+	clause, err := oc.clause(table)
+	if err != nil {
+		return "", fmt.Errorf("parse.Conflict: %w in table %q", err, table.Name)
+	}
+
+	return clause, nil
+}
+
+func (oc *OnConflict) clause(table *Table) (string, error) {
+	if table.Method == CopyMethod {
+		return "", fmt.Errorf("on_conflict not supported with method %q", CopyMethod)
+	}
+
+	var b strings.Builder
+	b.WriteString("on conflict")
+	if len(oc.Columns) > 0 {
+		fmt.Fprintf(&b, " (%s)", commaList(oc.Columns))
+	}
+
+	switch oc.Action {
+	case ConflictNothing:
+		b.WriteString(" do nothing")
+	case ConflictUpdate:
+		if len(oc.Columns) == 0 {
+			return "", fmt.Errorf("on_conflict %q requires columns", oc.Action)
+		}
+		// A statement can't update the same row twice,
+		// so the rows of a batch must not conflict with each other.
+		if table.BatchSize > 1 && !table.hasUnique(oc.Columns) {
+			return "", fmt.Errorf("on_conflict %q with batch_size %d requires a unique column in the conflict target", oc.Action, table.BatchSize)
+		}
+
+		update := oc.Update
+		if len(update) == 0 {
+			update = table.otherColumns(oc.Columns)
+		}
+		if len(update) == 0 {
+			return "", errors.New("on_conflict without columns to update")
+		}
+
+		set := make(commaList, len(update))
+		for i, name := range update {
+			set[i] = fmt.Sprintf("%s = excluded.%s", name, name)
+		}
+		fmt.Fprintf(&b, " do update set %s", set)
+	default:
+		return "", fmt.Errorf("unsupported on_conflict action %q", oc.Action)
+	}
+
+	return b.String(), nil
+}
+
+// hasUnique reports if any of the table's columns in names is Unique.
+func (table *Table) hasUnique(names []string) bool {
+	for _, name := range names {
+		for _, col := range table.Columns {
+			if col.Name == name && col.Unique {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// otherColumns returns the names of the table's columns,
+// which are not in names.
+func (table *Table) otherColumns(names []string) []string {
+	skip := make(map[string]bool, len(names))
+	for _, name := range names {
+		skip[name] = true
+	}
+
+	var other []string
+	for _, col := range table.Columns {
+		if !skip[col.Name] {
+			other = append(other, col.Name)
+		}
+	}
+
+	return other
+}
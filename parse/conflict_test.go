@@ -0,0 +1,157 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import "testing"
+
+func TestTable_Conflict(t *testing.T) {
+	columns := []*Column{
+		{Name: "id", Unique: true},
+		{Name: "name"},
+		{Name: "qty"},
+	}
+
+	tests := []struct {
+		name       string
+		method     Method
+		batchSize  int
+		onConflict *OnConflict
+		want       string
+		wantErr    bool
+	}{
+		{
+			"Not set",
+			"",
+			0,
+			nil,
+			"",
+			false,
+		},
+		{
+			"Copy",
+			CopyMethod,
+			0,
+			&OnConflict{Action: ConflictNothing},
+			"",
+			true,
+		},
+		{
+			"Unsupported action",
+			"",
+			0,
+			&OnConflict{Action: "foo"},
+			"",
+			true,
+		},
+		{
+			"Nothing",
+			"",
+			0,
+			&OnConflict{Action: ConflictNothing},
+			"on conflict do nothing",
+			false,
+		},
+		{
+			"Nothing with target",
+			InsertMethod,
+			0,
+			&OnConflict{Action: ConflictNothing, Columns: []string{"id"}},
+			"on conflict (id) do nothing",
+			false,
+		},
+		{
+			"Update without target",
+			"",
+			0,
+			&OnConflict{Action: ConflictUpdate},
+			"",
+			true,
+		},
+		{
+			"Update without columns",
+			"",
+			0,
+			&OnConflict{Action: ConflictUpdate, Columns: []string{"id", "name", "qty"}},
+			"",
+			true,
+		},
+		{
+			"Update other columns",
+			"",
+			0,
+			&OnConflict{Action: ConflictUpdate, Columns: []string{"id"}},
+			"on conflict (id) do update set name = excluded.name, qty = excluded.qty",
+			false,
+		},
+		{
+			"Update columns",
+			"",
+			0,
+			&OnConflict{Action: ConflictUpdate, Columns: []string{"id", "name"}, Update: []string{"qty"}},
+			"on conflict (id, name) do update set qty = excluded.qty",
+			false,
+		},
+		{
+			"Update batch",
+			"",
+			100,
+			&OnConflict{Action: ConflictUpdate, Columns: []string{"id"}},
+			"on conflict (id) do update set name = excluded.name, qty = excluded.qty",
+			false,
+		},
+		{
+			"Update batch without unique",
+			"",
+			100,
+			&OnConflict{Action: ConflictUpdate, Columns: []string{"name"}},
+			"",
+			true,
+		},
+		{
+			"Nothing batch without unique",
+			"",
+			100,
+			&OnConflict{Action: ConflictNothing, Columns: []string{"name"}},
+			"on conflict (name) do nothing",
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := &Table{
+				Name:       "articles",
+				Method:     tt.method,
+				BatchSize:  tt.batchSize,
+				OnConflict: tt.onConflict,
+				Columns:    columns,
+			}
+
+			got, err := table.Conflict()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Table.Conflict() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Table.Conflict() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
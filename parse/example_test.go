@@ -29,7 +29,9 @@ import (
 )
 
 var testConf = Config{
-	DSN: "dbname=testdata user=testdata host=db port=5432 connect_timeout=10",
+	DSN:         "dbname=testdata user=testdata host=db port=5432 connect_timeout=10",
+	Transaction: BatchTransaction,
+	CommitEvery: 500,
 	Tables: []*Table{
 		{
 			Name:   "all_supported",
@@ -38,6 +40,14 @@ var testConf = Config{
 				Table: time.Minute,
 				Exec:  time.Second,
 			},
+			BatchSize:       100,
+			Transaction:     AllTransaction,
+			Before:          BeforeDelete,
+			RestartIdentity: true,
+			OnConflict: &OnConflict{
+				Action:  ConflictUpdate,
+				Columns: []string{"uuid_v4_col"},
+			},
 			Columns: []*Column{
 				{
 					Name:            "bool_col_n",
@@ -57,6 +67,265 @@ var testConf = Config{
 						ProbabilityArg: 70.1,
 					},
 				},
+				{
+					Name:            "int4_col_n",
+					Seed:            3,
+					NullProbability: 10.0,
+					Type:            "int4",
+					Generator: map[ArgName]interface{}{
+						MinArg: -1000,
+						MaxArg: 1000,
+					},
+				},
+				{
+					Name:            "int4_col_nn",
+					Seed:            3,
+					NullProbability: 0.0,
+					Type:            "int4",
+					Generator: map[ArgName]interface{}{
+						MinArg: 1,
+						MaxArg: 1000000,
+					},
+				},
+				{
+					Name:            "int2_col",
+					Seed:            4,
+					NullProbability: 10.0,
+					Type:            "int2",
+					Generator: map[ArgName]interface{}{
+						MinArg: -100,
+						MaxArg: 100,
+					},
+				},
+				{
+					Name:            "int8_col",
+					Seed:            5,
+					NullProbability: 10.0,
+					Type:            "int8",
+					Generator: map[ArgName]interface{}{
+						MinArg: -1000000000000,
+						MaxArg: 1000000000000,
+					},
+				},
+				{
+					Name:            "numeric_col",
+					Seed:            6,
+					NullProbability: 10.0,
+					Type:            "numeric",
+					Generator: map[ArgName]interface{}{
+						MinArg:       -999.99,
+						MaxArg:       999.99,
+						PrecisionArg: 5,
+						ScaleArg:     2,
+					},
+				},
+				{
+					Name:            "float4_col",
+					Seed:            7,
+					NullProbability: 10.0,
+					Type:            "float4",
+					Generator: map[ArgName]interface{}{
+						MinArg: -1.5,
+						MaxArg: 1.5,
+					},
+				},
+				{
+					Name:            "float8_col",
+					Seed:            8,
+					NullProbability: 10.0,
+					Type:            "float8",
+					Generator: map[ArgName]interface{}{
+						DistArg:   "normal",
+						MeanArg:   100.5,
+						StdDevArg: 15.5,
+						MinArg:    0,
+					},
+				},
+				{
+					Name:            "text_col",
+					Seed:            9,
+					NullProbability: 10.0,
+					Type:            "text",
+					Generator: map[ArgName]interface{}{
+						MinLengthArg: 1,
+						MaxLengthArg: 100,
+						CharsetArg:   "unicode",
+					},
+				},
+				{
+					Name:            "varchar_col",
+					Seed:            10,
+					NullProbability: 10.0,
+					Type:            "varchar",
+					Generator: map[ArgName]interface{}{
+						LengthArg:  32,
+						CharsetArg: "printable",
+					},
+				},
+				{
+					Name:            "char_col",
+					Seed:            11,
+					NullProbability: 10.0,
+					Type:            "char",
+					Generator: map[ArgName]interface{}{
+						LengthArg:    8,
+						MinLengthArg: 8,
+						CharsetArg:   "hex",
+					},
+				},
+				{
+					Name:            "regex_col",
+					Seed:            12,
+					NullProbability: 0.0,
+					Type:            "varchar",
+					Generator: map[ArgName]interface{}{
+						LengthArg:    16,
+						RegexArg:     "[A-Z]{3}-[0-9]{6}(-[a-z]+)?",
+						MaxRepeatArg: 5,
+					},
+				},
+				{
+					Name:            "date_col",
+					Seed:            13,
+					NullProbability: 10.0,
+					Type:            "date",
+					Generator: map[ArgName]interface{}{
+						MinArg: "2000-01-01",
+						MaxArg: "2021-12-31",
+					},
+				},
+				{
+					Name:            "time_col",
+					Seed:            14,
+					NullProbability: 10.0,
+					Type:            "time",
+					Generator: map[ArgName]interface{}{
+						MinArg:         "08:00",
+						MaxArg:         "17:30",
+						GranularityArg: "minute",
+					},
+				},
+				{
+					Name:            "timestamp_col",
+					Seed:            15,
+					NullProbability: 10.0,
+					Type:            "timestamp",
+					Generator: map[ArgName]interface{}{
+						MinArg: "2021-01-01 00:00:00",
+						MaxArg: "2021-12-31 23:59:59",
+					},
+				},
+				{
+					Name:            "timestamptz_col",
+					Seed:            16,
+					NullProbability: 10.0,
+					Type:            "timestamptz",
+					Generator: map[ArgName]interface{}{
+						MinArg:         "-30d",
+						MaxArg:         "now",
+						GranularityArg: "millisecond",
+					},
+				},
+				{
+					Name:            "interval_col",
+					Seed:            17,
+					NullProbability: 10.0,
+					Type:            "interval",
+					Generator: map[ArgName]interface{}{
+						MinArg: "1m",
+						MaxArg: "7d",
+					},
+				},
+				{
+					Name:            "int8_seq_col",
+					Seed:            18,
+					NullProbability: 0.0,
+					Type:            "int8",
+					Generator: map[ArgName]interface{}{
+						ModeArg:  "sequence",
+						StartArg: 1,
+						StepArg:  1,
+					},
+				},
+				{
+					Name:            "timestamptz_seq_col",
+					Seed:            19,
+					NullProbability: 10.0,
+					Type:            "timestamptz",
+					Generator: map[ArgName]interface{}{
+						ModeArg:   "sequence",
+						StartArg:  "2021-01-01 00:00:00",
+						StepArg:   "1m",
+						JitterArg: "10s",
+					},
+				},
+				{
+					Name:            "uuid_v4_col",
+					Seed:            20,
+					NullProbability: 0.0,
+					Type:            "uuid",
+					Generator: map[ArgName]interface{}{
+						VersionArg: 4,
+					},
+					Unique: true,
+				},
+				{
+					Name:            "uuid_v7_col",
+					Seed:            21,
+					NullProbability: 0.0,
+					Type:            "uuid",
+					Generator: map[ArgName]interface{}{
+						VersionArg: 7,
+						MinArg:     "2021-01-01",
+						MaxArg:     "now",
+					},
+				},
+				{
+					Name:            "enum_col",
+					Seed:            22,
+					NullProbability: 0.0,
+					Type:            "enum",
+					Generator: map[ArgName]interface{}{
+						ChoiceArg: map[interface{}]interface{}{
+							"active":    80,
+							"suspended": 15,
+							"deleted":   5,
+						},
+					},
+				},
+				{
+					Name:            "int4_choice_col",
+					Seed:            23,
+					NullProbability: 10.0,
+					Type:            "int4",
+					Generator: map[ArgName]interface{}{
+						ChoiceArg: []interface{}{1, 2, 3, 5, 8},
+					},
+				},
+			},
+		},
+		{
+			Name:   "all_supported_references",
+			Amount: 100,
+			MaxDuration: TableDurations{
+				Table: time.Minute,
+				Exec:  time.Second,
+			},
+			Method:    CopyMethod,
+			ChunkSize: 50,
+			Before:    BeforeTruncateCascade,
+			Columns: []*Column{
+				{
+					Name:            "uuid_ref_col",
+					Seed:            24,
+					NullProbability: 0.0,
+					Type:            "reference",
+					Generator: map[ArgName]interface{}{
+						TableArg:  "all_supported",
+						ColumnArg: "uuid_v4_col",
+						SkewArg:   1.5,
+					},
+				},
 			},
 		},
 	},
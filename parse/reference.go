@@ -0,0 +1,225 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/muhlemmer/pg_testdata/generator"
+)
+
+// Reference to a column in another table.
+type Reference struct {
+	Table, Column string
+}
+
+func (r *Reference) String() string {
+	return fmt.Sprintf("%s.%s", r.Table, r.Column)
+}
+
+// reference returns the required table and column arguments.
+func (c *Column) reference() *Reference {
+	c.requiredGenOpts(ReferenceType, TableArg, ColumnArg)
+
+	return &Reference{
+		Table:  c.assertString(TableArg),
+		Column: c.assertString(ColumnArg),
+	}
+}
+
+// Reference returns the referenced table and column of a column with ReferenceType.
+// Nil is returned for columns of any other type.
+func (c *Column) Reference() (ref *Reference, err error) {
+	defer func() {
+		if err, _ = recover().(error); err != nil {
+			err = fmt.Errorf("parse.Column.Reference: %w", err)
+		}
+	}()
+
+	if c.Type != ReferenceType {
+		return nil, nil
+	}
+
+	return c.reference(), nil
+}
+
+// SetReferenced sets the values read from the referenced column,
+// from which the generator of a ReferenceType column picks.
+// It must be called before the InsertQuery of the column's table.
+func (c *Column) SetReferenced(values []generator.Encoded) {
+	c.referenced = values
+}
+
+// referenceType panics if the referenced values were not set.
+// The optional SkewArg must be 0 for a uniform distribution,
+// or greater than 1 for a Zipf distribution.
+func (c *Column) referenceType() generator.Value {
+	ref := c.reference()
+
+	var skew float64
+	if _, ok := c.Generator[SkewArg]; ok {
+		if skew = c.assertFloat64(SkewArg); skew != 0 && skew <= 1 {
+			c.panic(fmt.Errorf("%q %g not 0 or greater than 1", SkewArg, skew))
+		}
+	}
+
+	if len(c.referenced) == 0 {
+		c.panic(fmt.Errorf("no values of referenced column %s", ref))
+	}
+
+	return generator.NewReference(c.Seed, c.NullProbability, c.referenced, skew)
+}
+
+// references returns the names of the tables referenced by table.
+func (table *Table) references() []string {
+	var names []string
+
+	for _, col := range table.Columns {
+		if col.Type == ReferenceType {
+			names = append(names, col.reference().Table)
+		}
+	}
+
+	return names
+}
+
+// dependencies returns the names of the tables each table depends on,
+// from reference columns and deps.
+func (conf *Config) dependencies(deps map[string][]string) map[string][]string {
+	graph := make(map[string][]string, len(conf.Tables))
+	for _, table := range conf.Tables {
+		graph[table.Name] = nil
+	}
+	for _, table := range conf.Tables {
+		for _, name := range table.references() {
+			if _, ok := graph[name]; !ok {
+				panic(fmt.Errorf("table %q references table %q, which is not in the config", table.Name, name))
+			}
+			graph[table.Name] = append(graph[table.Name], name)
+		}
+		for _, name := range deps[table.Name] {
+			if _, ok := graph[name]; ok && name != table.Name {
+				graph[table.Name] = append(graph[table.Name], name)
+			}
+		}
+	}
+
+	return graph
+}
+
+// Dependencies returns the names of the tables each table must be inserted after,
+// from reference columns and deps, as filtered by InsertOrder.
+// An error is returned if a referenced table of a reference column is not in the config.
+func (conf *Config) Dependencies(deps map[string][]string) (graph map[string][]string, err error) {
+	defer func() {
+		if err, _ = recover().(error); err != nil {
+			err = fmt.Errorf("parse.Dependencies: %w", err)
+		}
+	}()
+
+	return conf.dependencies(deps), nil
+}
+
+// InsertOrder returns the tables in an order in which they can be inserted,
+// so that referenced tables are inserted before the tables referencing them.
+// Otherwise, the order of the config is kept.
+//
+// Besides reference columns, deps holds dependencies of tables on
+// the tables they reference, such as foreign keys read from the database.
+// Referenced tables which are not in the config
+// and tables referencing themselves are ignored in deps.
+//
+// An error is returned if a referenced table of a reference column is not in the config,
+// or if tables reference each other, which reports the cycle.
+func (conf *Config) InsertOrder(deps map[string][]string) (tables []*Table, err error) {
+	defer func() {
+		if err, _ = recover().(error); err != nil {
+			err = fmt.Errorf("parse.InsertOrder: %w", err)
+		}
+	}()
+
+	graph := conf.dependencies(deps)
+	done := make(map[string]bool, len(conf.Tables))
+
+	for len(tables) < len(conf.Tables) {
+		var next *Table
+
+		for _, table := range conf.Tables {
+			if !done[table.Name] && allDone(done, graph[table.Name]) {
+				next = table
+				break
+			}
+		}
+		if next == nil {
+			panic(fmt.Errorf("circular references between tables: %s", strings.Join(conf.cycle(graph, done), " -> ")))
+		}
+
+		done[next.Name] = true
+		tables = append(tables, next)
+	}
+
+	return tables, nil
+}
+
+func allDone(done map[string]bool, names []string) bool {
+	for _, name := range names {
+		if !done[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// cycle returns a path of table names which reference each other,
+// from the first table in config order which is not done.
+// The path starts and ends with the same table.
+// It must only be called if such a cycle exists.
+func (conf *Config) cycle(graph map[string][]string, done map[string]bool) []string {
+	var start string
+	for _, table := range conf.Tables {
+		if !done[table.Name] {
+			start = table.Name
+			break
+		}
+	}
+
+	// Every table which is not done references at least one other table
+	// which is not done, so following them must lead to a table already visited.
+	var path []string
+	visited := make(map[string]int)
+
+	for name := start; ; {
+		if i, ok := visited[name]; ok {
+			return append(path[i:], name)
+		}
+
+		visited[name] = len(path)
+		path = append(path, name)
+
+		for _, ref := range graph[name] {
+			if !done[ref] {
+				name = ref
+				break
+			}
+		}
+	}
+}
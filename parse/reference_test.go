@@ -0,0 +1,301 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/muhlemmer/pg_testdata/generator"
+)
+
+func TestColumn_Reference(t *testing.T) {
+	tests := []struct {
+		name    string
+		col     *Column
+		want    *Reference
+		wantErr bool
+	}{
+		{
+			"other type",
+			&Column{Type: BoolType},
+			nil,
+			false,
+		},
+		{
+			"missing arg",
+			&Column{
+				Type:      ReferenceType,
+				Generator: map[ArgName]interface{}{TableArg: "foo"},
+			},
+			nil,
+			true,
+		},
+		{
+			"reference",
+			&Column{
+				Type:      ReferenceType,
+				Generator: map[ArgName]interface{}{TableArg: "foo", ColumnArg: "id"},
+			},
+			&Reference{"foo", "id"},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.col.Reference()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Column.Reference() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Column.Reference() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_column_referenceType(t *testing.T) {
+	values := []generator.Encoded{
+		{Text: []byte("1")},
+	}
+
+	tests := []struct {
+		name       string
+		Generator  map[ArgName]interface{}
+		referenced []generator.Encoded
+		want       generator.Value
+		wantErr    bool
+	}{
+		{
+			"missing arg",
+			map[ArgName]interface{}{ColumnArg: "id"},
+			values,
+			nil,
+			true,
+		},
+		{
+			"not referenced",
+			map[ArgName]interface{}{TableArg: "foo", ColumnArg: "id"},
+			nil,
+			nil,
+			true,
+		},
+		{
+			"skew out of range",
+			map[ArgName]interface{}{TableArg: "foo", ColumnArg: "id", SkewArg: 0.5},
+			values,
+			nil,
+			true,
+		},
+		{
+			"uniform",
+			map[ArgName]interface{}{TableArg: "foo", ColumnArg: "id"},
+			values,
+			generator.NewReference(1, 2, values, 0),
+			false,
+		},
+		{
+			"skew",
+			map[ArgName]interface{}{TableArg: "foo", ColumnArg: "id", SkewArg: 1.5},
+			values,
+			generator.NewReference(1, 2, values, 1.5),
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Column{
+				Seed:            1,
+				NullProbability: 2,
+				Type:            ReferenceType,
+				Generator:       tt.Generator,
+			}
+			c.SetReferenced(tt.referenced)
+
+			err := func() (err error) {
+				defer func() { err, _ = recover().(error) }()
+				if got := c.valueGenerator(1000); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Column.valueGenerator() = %v, want %v", got, tt.want)
+				}
+				return nil
+			}()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("column.valueGenerator() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
+
+func referencingTable(name string, refs ...string) *Table {
+	table := &Table{Name: name}
+
+	for _, ref := range refs {
+		table.Columns = append(table.Columns, &Column{
+			Name:      ref + "_id",
+			Type:      ReferenceType,
+			Generator: map[ArgName]interface{}{TableArg: ref, ColumnArg: "id"},
+		})
+	}
+
+	return table
+}
+
+func tableNames(tables []*Table) []string {
+	names := make([]string, len(tables))
+	for i, table := range tables {
+		names[i] = table.Name
+	}
+	return names
+}
+
+func TestConfig_InsertOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		tables  []*Table
+		deps    map[string][]string
+		want    []string
+		wantErr string
+	}{
+		{
+			"config order",
+			[]*Table{
+				referencingTable("a"),
+				referencingTable("b"),
+			},
+			nil,
+			[]string{"a", "b"},
+			"",
+		},
+		{
+			"dependencies",
+			[]*Table{
+				referencingTable("order_lines", "orders", "products"),
+				referencingTable("orders", "customers"),
+				referencingTable("customers"),
+				referencingTable("products"),
+			},
+			nil,
+			[]string{"customers", "orders", "products", "order_lines"},
+			"",
+		},
+		{
+			"foreign keys",
+			[]*Table{
+				referencingTable("order_lines"),
+				referencingTable("orders"),
+				referencingTable("customers"),
+				referencingTable("employees"),
+			},
+			map[string][]string{
+				"order_lines": {"orders", "products"},
+				"orders":      {"customers"},
+				"employees":   {"employees"},
+			},
+			[]string{"customers", "orders", "order_lines", "employees"},
+			"",
+		},
+		{
+			"missing table",
+			[]*Table{
+				referencingTable("orders", "customers"),
+			},
+			nil,
+			nil,
+			`parse.InsertOrder: table "orders" references table "customers", which is not in the config`,
+		},
+		{
+			"circular",
+			[]*Table{
+				referencingTable("a", "b"),
+				referencingTable("b", "c"),
+				referencingTable("c"),
+			},
+			map[string][]string{
+				"c": {"b"},
+			},
+			nil,
+			"parse.InsertOrder: circular references between tables: b -> c -> b",
+		},
+		{
+			"self reference",
+			[]*Table{
+				referencingTable("a", "a"),
+			},
+			nil,
+			nil,
+			"parse.InsertOrder: circular references between tables: a -> a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := &Config{Tables: tt.tables}
+
+			got, err := conf.InsertOrder(tt.deps)
+			if err != nil {
+				if err.Error() != tt.wantErr {
+					t.Errorf("Config.InsertOrder() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if tt.wantErr != "" {
+				t.Errorf("Config.InsertOrder() error = nil, wantErr %v", tt.wantErr)
+			}
+			if !reflect.DeepEqual(tableNames(got), tt.want) {
+				t.Errorf("Config.InsertOrder() = %v, want %v", tableNames(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_Dependencies(t *testing.T) {
+	conf := &Config{
+		Tables: []*Table{
+			referencingTable("order_lines", "orders"),
+			referencingTable("orders"),
+			referencingTable("customers"),
+		},
+	}
+
+	got, err := conf.Dependencies(map[string][]string{
+		"order_lines": {"products"},
+		"orders":      {"customers", "orders"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]string{
+		"order_lines": {"orders"},
+		"orders":      {"customers"},
+		"customers":   nil,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Config.Dependencies() = %v, want %v", got, want)
+	}
+
+	conf.Tables = conf.Tables[:1]
+	if _, err = conf.Dependencies(nil); err == nil {
+		t.Error("Config.Dependencies() error = nil, want error for missing table")
+	}
+}
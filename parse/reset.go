@@ -0,0 +1,143 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Before is the strategy to reset the rows of a table, before loading.
+type Before string
+
+const (
+	BeforeNone            Before = "none"             // Keep existing rows.
+	BeforeTruncate        Before = "truncate"         // Truncate the table.
+	BeforeTruncateCascade Before = "truncate cascade" // Truncate the table and all tables referencing it.
+	BeforeDelete          Before = "delete"           // Delete all rows, which fires delete triggers.
+)
+
+// restartIdentityQuery restarts the sequences owned by the columns of a table,
+// for serial and identity columns. The parameter is the quoted name of the table.
+const restartIdentityQuery = `select setval(d.objid::regclass, s.seqstart, false)
+from pg_depend d
+join pg_sequence s on s.seqrelid = d.objid
+where d.classid = 'pg_class'::regclass and d.refobjid = $1::regclass and d.deptype in ('a', 'i');`
+
+// Query is an SQL statement with the arguments of its parameters.
+type Query struct {
+	SQL  string
+	Args []interface{}
+}
+
+// String returns the statement with its arguments as literals,
+// which must be strings.
+func (q Query) String() string {
+	stmt := q.SQL
+	for i := len(q.Args); i > 0; i-- {
+		literal := "'" + strings.ReplaceAll(fmt.Sprint(q.Args[i-1]), "'", "''") + "'"
+		stmt = strings.ReplaceAll(stmt, fmt.Sprintf("$%d", i), literal)
+	}
+	return stmt
+}
+
+// quoteIdentifier quotes a table name, which may be qualified with a schema,
+// like pgx.Identifier.
+func quoteIdentifier(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = `"` + strings.ReplaceAll(part, `"`, `""`) + `"`
+	}
+	return strings.Join(parts, ".")
+}
+
+// BeforeLoad returns the reset strategy of the table.
+// If the table does not set Before, it defaults to BeforeTruncate
+// when reset is true and to BeforeNone otherwise.
+func (table *Table) BeforeLoad(reset bool) (Before, error) {
+	switch table.Before {
+	case "":
+		if reset {
+			return BeforeTruncate, nil
+		}
+		return BeforeNone, nil
+	case BeforeNone, BeforeTruncate, BeforeTruncateCascade, BeforeDelete:
+		return table.Before, nil
+	default:
+		return "", fmt.Errorf("parse.BeforeLoad: unsupported before %q in table %q", table.Before, table.Name)
+	}
+}
+
+// ResetQueries returns the statements resetting tables, which must be in insert order,
+// as returned by InsertOrder. They must be executed in the returned order.
+// Reset is the default for tables which do not set Before, see BeforeLoad.
+//
+// Rows are deleted from tables in reverse insert order,
+// so that referencing rows are deleted first.
+// Tables are truncated with a single statement, so tables referencing each other
+// can be truncated together. The statement cascades if any of the tables uses BeforeTruncateCascade.
+// Finally, the identity sequences are restarted for tables with RestartIdentity.
+// Table names are quoted, so they may be qualified with a schema, but not quoted.
+func ResetQueries(tables []*Table, reset bool) ([]Query, error) {
+	var (
+		queries  []Query
+		truncate commaList
+		cascade  bool
+	)
+
+	for i := len(tables) - 1; i >= 0; i-- {
+		table := tables[i]
+
+		before, err := table.BeforeLoad(reset)
+		if err != nil {
+			return nil, fmt.Errorf("parse.ResetQueries: %w", err)
+		}
+
+		switch before {
+		case BeforeDelete:
+			queries = append(queries, Query{SQL: fmt.Sprintf("delete from %s;", quoteIdentifier(table.Name))})
+		case BeforeTruncateCascade:
+			cascade = true
+			fallthrough
+		case BeforeTruncate:
+			truncate = append(truncate, quoteIdentifier(table.Name))
+		}
+	}
+
+	if len(truncate) > 0 {
+		var b strings.Builder
+		fmt.Fprintf(&b, "truncate table %s", truncate)
+		if cascade {
+			b.WriteString(" cascade")
+		}
+		b.WriteString(";")
+
+		queries = append(queries, Query{SQL: b.String()})
+	}
+
+	for _, table := range tables {
+		if table.RestartIdentity {
+			queries = append(queries, Query{SQL: restartIdentityQuery, Args: []interface{}{quoteIdentifier(table.Name)}})
+		}
+	}
+
+	return queries, nil
+}
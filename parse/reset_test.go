@@ -0,0 +1,166 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTable_BeforeLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		before  Before
+		reset   bool
+		want    Before
+		wantErr bool
+	}{
+		{"Default", "", false, BeforeNone, false},
+		{"Default reset", "", true, BeforeTruncate, false},
+		{"None reset", BeforeNone, true, BeforeNone, false},
+		{"Truncate", BeforeTruncate, false, BeforeTruncate, false},
+		{"Truncate cascade", BeforeTruncateCascade, false, BeforeTruncateCascade, false},
+		{"Delete", BeforeDelete, true, BeforeDelete, false},
+		{"Unsupported", "drop", false, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := &Table{Name: "articles", Before: tt.before}
+
+			got, err := table.BeforeLoad(tt.reset)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Table.BeforeLoad() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Table.BeforeLoad() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResetQueries(t *testing.T) {
+	tests := []struct {
+		name    string
+		tables  []*Table
+		reset   bool
+		want    []Query
+		wantErr bool
+	}{
+		{
+			"None",
+			[]*Table{
+				{Name: "a"},
+				{Name: "b", Before: BeforeNone},
+			},
+			false,
+			nil,
+			false,
+		},
+		{
+			"Unsupported",
+			[]*Table{
+				{Name: "a", Before: "drop"},
+			},
+			false,
+			nil,
+			true,
+		},
+		{
+			"Reset",
+			[]*Table{
+				{Name: "a"},
+				{Name: "b", Before: BeforeNone},
+				{Name: "c"},
+			},
+			true,
+			[]Query{{SQL: `truncate table "c", "a";`}},
+			false,
+		},
+		{
+			"Strategies",
+			[]*Table{
+				{Name: "a", Before: BeforeDelete},
+				{Name: "b", Before: BeforeTruncate, RestartIdentity: true},
+				{Name: "c", Before: BeforeTruncateCascade},
+				{Name: "d", Before: BeforeDelete, RestartIdentity: true},
+			},
+			false,
+			[]Query{
+				{SQL: `delete from "d";`},
+				{SQL: `delete from "a";`},
+				{SQL: `truncate table "c", "b" cascade;`},
+				{SQL: restartIdentityQuery, Args: []interface{}{`"b"`}},
+				{SQL: restartIdentityQuery, Args: []interface{}{`"d"`}},
+			},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResetQueries(tt.tables, tt.reset)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ResetQueries() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ResetQueries() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuery_String(t *testing.T) {
+	tests := []struct {
+		name  string
+		query Query
+		want  string
+	}{
+		{"No args", Query{SQL: "select 1;"}, "select 1;"},
+		{"Args", Query{SQL: "select $1, $2;", Args: []interface{}{"a", "b"}}, "select 'a', 'b';"},
+		{"Quote", Query{SQL: "select $1::regclass;", Args: []interface{}{`"it's"`}}, `select '"it''s"'::regclass;`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query.String(); got != tt.want {
+				t.Errorf("Query.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_quoteIdentifier(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"foo", `"foo"`},
+		{"public.Foo", `"public"."Foo"`},
+		{`it's "x"`, `"it's ""x"""`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := quoteIdentifier(tt.name); got != tt.want {
+				t.Errorf("quoteIdentifier() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
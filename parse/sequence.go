@@ -0,0 +1,174 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/muhlemmer/pg_testdata/generator"
+)
+
+// Generation modes, accepted by ModeArg.
+const (
+	RandomMode   = "random"
+	SequenceMode = "sequence"
+)
+
+// mode returns the ModeArg, which defaults to RandomMode.
+func (c *Column) mode() string {
+	if _, ok := c.Generator[ModeArg]; !ok {
+		return RandomMode
+	}
+
+	switch mode := c.assertString(ModeArg); mode {
+	case RandomMode, SequenceMode:
+		return mode
+	default:
+		c.panic(fmt.Errorf("unsupported %q %q", ModeArg, mode))
+		return ""
+	}
+}
+
+// checkSequence panics if any of the amount values of a sequence,
+// including jitter, does not fit between lower and upper.
+func (c *Column) checkSequence(tp TypeName, amount int, start, step, jitter, lower, upper int64) {
+	if jitter < 0 {
+		c.panic(fmt.Errorf("negative %q %d", JitterArg, jitter))
+	}
+	if amount < 1 {
+		amount = 1
+	}
+
+	first := big.NewInt(start)
+	last := new(big.Int).Mul(big.NewInt(step), big.NewInt(int64(amount-1)))
+	last.Add(last, first)
+
+	if first.Cmp(last) > 0 {
+		first, last = last, first
+	}
+
+	j := big.NewInt(jitter)
+	first.Sub(first, j)
+	last.Add(last, j)
+
+	if first.Cmp(big.NewInt(lower)) < 0 || last.Cmp(big.NewInt(upper)) > 0 {
+		c.panic(fmt.Errorf("sequence of %d values from %s to %s out of range for type %q", amount, first, last, tp))
+	}
+}
+
+// intSequence returns the optional start, step and jitter arguments
+// of an integer sequence. Start and step default to 1, jitter to 0.
+func (c *Column) intSequence(tp TypeName, amount int, lower, upper int64) (start, step, jitter int64) {
+	start, step = 1, 1
+
+	if _, ok := c.Generator[StartArg]; ok {
+		start = c.assertInt64(StartArg)
+	}
+	if _, ok := c.Generator[StepArg]; ok {
+		step = c.assertInt64(StepArg)
+	}
+	if _, ok := c.Generator[JitterArg]; ok {
+		jitter = c.assertInt64(JitterArg)
+	}
+
+	c.checkSequence(tp, amount, start, step, jitter, lower, upper)
+
+	return start, step, jitter
+}
+
+// timeSequence returns the required start and step arguments,
+// with optional jitter which defaults to 0.
+// The offset of the last value from start, in microseconds, must fit an int64.
+func (c *Column) timeSequence(tp TypeName, amount int) (start time.Time, step, jitter time.Duration) {
+	c.requiredGenOpts(tp, StartArg, StepArg)
+
+	start, step = c.assertTime(StartArg), c.assertDuration(StepArg)
+	if _, ok := c.Generator[JitterArg]; ok {
+		jitter = c.assertDuration(JitterArg)
+	}
+
+	const maxMicros = math.MaxInt64 / int64(time.Microsecond)
+	c.checkSequence(tp, amount, 0, int64(step/time.Microsecond), int64(jitter/time.Microsecond), -maxMicros, maxMicros)
+
+	return start, step, jitter
+}
+
+// assertDays returns the duration argument arg in whole days.
+func (c *Column) assertDays(arg ArgName) int64 {
+	d := c.assertDuration(arg)
+	if d%generator.Day != 0 {
+		c.panic(fmt.Errorf("%q %v not a multiple of a day", arg, d))
+	}
+
+	return int64(d / generator.Day)
+}
+
+// dateSequence returns the required start argument and the optional
+// step and jitter, which default to 1 and 0 days.
+func (c *Column) dateSequence(amount int) (start time.Time, step, jitter int64) {
+	c.requiredGenOpts(DateType, StartArg)
+
+	start, step = c.assertTime(StartArg), 1
+	if _, ok := c.Generator[StepArg]; ok {
+		step = c.assertDays(StepArg)
+	}
+	if _, ok := c.Generator[JitterArg]; ok {
+		jitter = c.assertDays(JitterArg)
+	}
+
+	c.checkSequence(DateType, amount, 0, step, jitter, math.MinInt32, math.MaxInt32)
+
+	return start, step, jitter
+}
+
+// sequenceGenerator panics if Type does not support SequenceMode.
+// For columns of a chunk, the sequence continues after the rows
+// of the preceding chunks.
+func (c *Column) sequenceGenerator(amount int) generator.Value {
+	total, offset := c.offset+amount, int64(c.offset)
+
+	switch c.Type {
+	case Int2Type:
+		start, step, jitter := c.intSequence(Int2Type, total, math.MinInt16, math.MaxInt16)
+		return generator.NewInt2Sequence(c.Seed, c.NullProbability, int16(start+offset*step), int16(step), int16(jitter))
+	case Int4Type:
+		start, step, jitter := c.intSequence(Int4Type, total, math.MinInt32, math.MaxInt32)
+		return generator.NewInt4Sequence(c.Seed, c.NullProbability, int32(start+offset*step), int32(step), int32(jitter))
+	case Int8Type:
+		start, step, jitter := c.intSequence(Int8Type, total, math.MinInt64, math.MaxInt64)
+		return generator.NewInt8Sequence(c.Seed, c.NullProbability, start+offset*step, step, jitter)
+	case TimestampType:
+		start, step, jitter := c.timeSequence(TimestampType, total)
+		return generator.NewTimestampSequence(c.Seed, c.NullProbability, start.Add(time.Duration(offset)*step.Truncate(time.Microsecond)), step, jitter)
+	case TimestamptzType:
+		start, step, jitter := c.timeSequence(TimestamptzType, total)
+		return generator.NewTimestamptzSequence(c.Seed, c.NullProbability, start.Add(time.Duration(offset)*step.Truncate(time.Microsecond)), step, jitter)
+	case DateType:
+		start, step, jitter := c.dateSequence(total)
+		return generator.NewDateSequence(c.Seed, c.NullProbability, start.AddDate(0, 0, int(offset*step)), int(step), int(jitter))
+	default:
+		c.panic(fmt.Errorf("%q %q unsupported for type %q", ModeArg, SequenceMode, c.Type))
+		return nil
+	}
+}
@@ -0,0 +1,268 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/muhlemmer/pg_testdata/generator"
+)
+
+func Test_column_sequenceGenerator(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		Type      TypeName
+		amount    int
+		Generator map[ArgName]interface{}
+		want      generator.Value
+		wantErr   bool
+	}{
+		{
+			"unsupported mode",
+			Int4Type,
+			10,
+			map[ArgName]interface{}{ModeArg: "foo"},
+			nil,
+			true,
+		},
+		{
+			"unsupported type",
+			BoolType,
+			10,
+			map[ArgName]interface{}{ModeArg: SequenceMode},
+			nil,
+			true,
+		},
+		{
+			"int4 defaults",
+			Int4Type,
+			10,
+			map[ArgName]interface{}{ModeArg: SequenceMode},
+			generator.NewInt4Sequence(1, 2, 1, 1, 0),
+			false,
+		},
+		{
+			"int2 out of range",
+			Int2Type,
+			40000,
+			map[ArgName]interface{}{ModeArg: SequenceMode},
+			nil,
+			true,
+		},
+		{
+			"int2 negative jitter",
+			Int2Type,
+			10,
+			map[ArgName]interface{}{ModeArg: SequenceMode, JitterArg: -1},
+			nil,
+			true,
+		},
+		{
+			"int2 jitter out of range",
+			Int2Type,
+			10,
+			map[ArgName]interface{}{ModeArg: SequenceMode, StartArg: -32768, JitterArg: 1},
+			nil,
+			true,
+		},
+		{
+			"int2",
+			Int2Type,
+			10,
+			map[ArgName]interface{}{ModeArg: SequenceMode, StartArg: 100, StepArg: -10, JitterArg: 2},
+			generator.NewInt2Sequence(1, 2, 100, -10, 2),
+			false,
+		},
+		{
+			"int8 overflow",
+			Int8Type,
+			3,
+			map[ArgName]interface{}{ModeArg: SequenceMode, StartArg: 1, StepArg: 1 << 62},
+			nil,
+			true,
+		},
+		{
+			"int8",
+			Int8Type,
+			2,
+			map[ArgName]interface{}{ModeArg: SequenceMode, StartArg: 1, StepArg: 1 << 62},
+			generator.NewInt8Sequence(1, 2, 1, 1<<62, 0),
+			false,
+		},
+		{
+			"timestamp missing arg",
+			TimestampType,
+			10,
+			map[ArgName]interface{}{ModeArg: SequenceMode, StartArg: "2021-01-01"},
+			nil,
+			true,
+		},
+		{
+			"timestamp overflow",
+			TimestampType,
+			1000000,
+			map[ArgName]interface{}{ModeArg: SequenceMode, StartArg: "2021-01-01", StepArg: "36500d"},
+			nil,
+			true,
+		},
+		{
+			"timestamp",
+			TimestampType,
+			10,
+			map[ArgName]interface{}{ModeArg: SequenceMode, StartArg: "2021-01-01", StepArg: "1m", JitterArg: "10s"},
+			generator.NewTimestampSequence(1, 2, start, time.Minute, 10*time.Second),
+			false,
+		},
+		{
+			"timestamptz",
+			TimestamptzType,
+			10,
+			map[ArgName]interface{}{ModeArg: SequenceMode, StartArg: "-1d", StepArg: "1h"},
+			generator.NewTimestamptzSequence(1, 2, testNow.Add(-generator.Day), time.Hour, 0),
+			false,
+		},
+		{
+			"date missing arg",
+			DateType,
+			10,
+			map[ArgName]interface{}{ModeArg: SequenceMode},
+			nil,
+			true,
+		},
+		{
+			"date partial day",
+			DateType,
+			10,
+			map[ArgName]interface{}{ModeArg: SequenceMode, StartArg: "2021-01-01", StepArg: "1d12h"},
+			nil,
+			true,
+		},
+		{
+			"date",
+			DateType,
+			10,
+			map[ArgName]interface{}{ModeArg: SequenceMode, StartArg: "2021-01-01", StepArg: "7d", JitterArg: "1d"},
+			generator.NewDateSequence(1, 2, start, 7, 1),
+			false,
+		},
+		{
+			"random mode",
+			Int4Type,
+			10,
+			map[ArgName]interface{}{ModeArg: RandomMode, MinArg: 1, MaxArg: 10},
+			generator.NewInt4(1, 2, 1, 10),
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Column{
+				Seed:            1,
+				NullProbability: 2,
+				Type:            tt.Type,
+				Generator:       tt.Generator,
+			}
+
+			err := func() (err error) {
+				defer func() { err, _ = recover().(error) }()
+				if got := c.valueGenerator(tt.amount); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Column.valueGenerator() = %v, want %v", got, tt.want)
+				}
+				return nil
+			}()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("column.valueGenerator() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
+
+func Test_column_sequenceGenerator_offset(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		Type      TypeName
+		Generator map[ArgName]interface{}
+		want      generator.Value
+		wantErr   bool
+	}{
+		{
+			"int4",
+			Int4Type,
+			map[ArgName]interface{}{ModeArg: SequenceMode, StartArg: 100, StepArg: -2},
+			generator.NewInt4Sequence(1, 2, 80, -2, 0),
+			false,
+		},
+		{
+			"int2 out of range",
+			Int2Type,
+			map[ArgName]interface{}{ModeArg: SequenceMode, StartArg: math.MaxInt16 - 15},
+			nil,
+			true,
+		},
+		{
+			"timestamp",
+			TimestampType,
+			map[ArgName]interface{}{ModeArg: SequenceMode, StartArg: start, StepArg: "1h"},
+			generator.NewTimestampSequence(1, 2, start.Add(10*time.Hour), time.Hour, 0),
+			false,
+		},
+		{
+			"date",
+			DateType,
+			map[ArgName]interface{}{ModeArg: SequenceMode, StartArg: start, StepArg: "48h"},
+			generator.NewDateSequence(1, 2, start.AddDate(0, 0, 20), 2, 0),
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Column{
+				Seed:            1,
+				NullProbability: 2,
+				Type:            tt.Type,
+				Generator:       tt.Generator,
+				offset:          10,
+			}
+
+			err := func() (err error) {
+				defer func() { err, _ = recover().(error) }()
+				if got := c.valueGenerator(10); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Column.valueGenerator() = %v, want %v", got, tt.want)
+				}
+				return nil
+			}()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("column.valueGenerator() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
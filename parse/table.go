@@ -21,13 +21,14 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 package parse
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"text/template"
 	"time"
 )
 
-const insertQuery = "insert into {{ .Table }} ({{ .Columns }}) values ({{ .Positions }});"
+const insertQuery = "insert into {{ .Table }} ({{ .Columns }}) values {{ range $i, $row := .Rows }}{{ if $i }}, {{ end }}({{ $row }}){{ end }}{{ with .Conflict }} {{ . }}{{ end }};"
 
 var insertTmpl = template.Must(template.New("insertQuery").Parse(insertQuery))
 
@@ -38,45 +39,101 @@ func (l commaList) String() string {
 }
 
 type insertData struct {
-	Table     string
-	Columns   commaList
-	Positions commaList
+	Table    string
+	Columns  commaList
+	Rows     []commaList // Positions of the parameters in each row.
+	Conflict string      // On conflict clause.
 }
 
+// maxParameters is the maximum amount of parameters in a single statement,
+// as imposed by the PostgreSQL wire protocol.
+const maxParameters = 65535
+
+// Method of loading the rows of a table.
+type Method string
+
+const (
+	InsertMethod Method = "insert" // Prepared insert statement for each row, the default.
+	CopyMethod   Method = "copy"   // Bulk loading with COPY FROM STDIN.
+)
+
+// TableDurations limit the time of loading a Table and of each statement.
+// A zero duration means no limit.
 type TableDurations struct {
 	Table, Exec time.Duration
 }
 
 // Table definition
 type Table struct {
-	Name        string         // Name of the Table
-	Amount      int            // Amount of Rows to generate and insert
-	MaxDuration TableDurations `yaml:"max_duration"`
-	Columns     []*Column
+	Name            string         // Name of the Table
+	Amount          int            // Amount of Rows to generate and insert
+	MaxDuration     TableDurations `yaml:"max_duration"`
+	Method          Method         `yaml:",omitempty"`                 // Method of loading the rows, defaults to InsertMethod.
+	BatchSize       int            `yaml:"batch_size,omitempty"`       // Rows per insert statement, defaults to 1. Only used with InsertMethod.
+	ChunkSize       int            `yaml:"chunk_size,omitempty"`       // Rows per chunk, which can be loaded in parallel. Disabled by default.
+	Transaction     Transaction    `yaml:",omitempty"`                 // Transaction mode, defaults to the Transaction of the Config.
+	CommitEvery     int            `yaml:"commit_every,omitempty"`     // Rows per transaction for BatchTransaction, defaults to CommitEvery of the Config.
+	Before          Before         `yaml:",omitempty"`                 // Strategy to reset the table before loading, see BeforeLoad.
+	RestartIdentity bool           `yaml:"restart_identity,omitempty"` // Restart the sequences of serial and identity columns before loading.
+	OnConflict      *OnConflict    `yaml:"on_conflict,omitempty"`      // Handling of rows violating a unique constraint, with InsertMethod.
+	Columns         []*Column
 }
 
-func (table *Table) insert(tmpl *template.Template) (string, []interface{}) {
-	data := insertData{
-		Table:     table.Name,
-		Columns:   make(commaList, len(table.Columns)),
-		Positions: make(commaList, len(table.Columns)),
-	}
-
+// columns returns the column names and a value generator for each column.
+func (table *Table) columns() ([]string, []interface{}) {
+	names := make([]string, len(table.Columns))
 	args := make([]interface{}, len(table.Columns))
-	var err error
 
 	for i, col := range table.Columns {
-		data.Columns[i] = col.Name
-		data.Positions[i] = fmt.Sprintf("$%d", i+1)
-		args[i] = col.valueGenerator()
+		names[i] = col.Name
+		args[i] = col.valueGenerator(table.Amount)
+	}
+
+	return names, args
+}
+
+// render executes tmpl for the rows of values,
+// which are parameter positions or literals in column order.
+func (table *Table) render(tmpl *template.Template, names []string, rows []commaList) string {
+	data := insertData{
+		Table:   table.Name,
+		Columns: commaList(names),
+		Rows:    rows,
+	}
+	if table.OnConflict != nil {
+		var err error
+		if data.Conflict, err = table.OnConflict.clause(table); err != nil {
+			panic(err)
+		}
 	}
 
 	var buf strings.Builder
-	if err = tmpl.Execute(&buf, &data); err != nil {
+	if err := tmpl.Execute(&buf, &data); err != nil {
 		panic(err)
 	}
 
-	return buf.String(), args
+	return buf.String()
+}
+
+// insert builds a statement inserting rows with tmpl.
+// The returned args contain the value generators of each column,
+// repeated for each row.
+func (table *Table) insert(tmpl *template.Template, rows int) (string, []interface{}) {
+	names, cols := table.columns()
+
+	positions := make([]commaList, rows)
+	args := make([]interface{}, 0, rows*len(cols))
+
+	for r := range positions {
+		positions[r] = make(commaList, len(names))
+
+		for i := range names {
+			positions[r][i] = fmt.Sprintf("$%d", len(args)+i+1)
+		}
+		args = append(args, cols...)
+	}
+
+	return table.render(tmpl, names, positions), args
 }
 
 // InsertQuery with args for this table.
@@ -91,6 +148,175 @@ func (table *Table) InsertQuery() (stmt string, args []interface{}, err error) {
 		}
 	}()
 
-	stmt, args = table.insert(insertTmpl)
+	stmt, args = table.insert(insertTmpl, 1)
 	return
 }
+
+// LiteralInsertQuery returns a statement inserting rows of literal values,
+// like BatchInsertQuery. Each row must hold a SQL literal for each column,
+// such as a quoted string or null.
+func (table *Table) LiteralInsertQuery(rows [][]string) (stmt string, err error) {
+	defer func() {
+		if err, _ = recover().(error); err != nil {
+			err = fmt.Errorf("parse.LiteralInsertQuery: %w in table %q", err, table.Name)
+		}
+	}()
+
+	if len(rows) == 0 {
+		panic(errors.New("no rows"))
+	}
+
+	names := make([]string, len(table.Columns))
+	for i, col := range table.Columns {
+		names[i] = col.Name
+	}
+
+	literals := make([]commaList, len(rows))
+	for r, row := range rows {
+		if len(row) != len(names) {
+			panic(fmt.Errorf("row %d has %d values for %d columns", r, len(row), len(names)))
+		}
+		literals[r] = commaList(row)
+	}
+
+	return table.render(insertTmpl, names, literals), nil
+}
+
+// Batch returns the amount of rows to insert with a single statement.
+// It defaults to 1 and is never larger than the Amount of rows in the table.
+// An error is returned if the BatchSize is negative or the statement would
+// exceed the maximum amount of parameters in a statement.
+func (table *Table) Batch() (int, error) {
+	size := table.BatchSize
+	if size < 0 {
+		return 0, fmt.Errorf("parse.Batch: negative batch_size %d in table %q", size, table.Name)
+	}
+	if size == 0 {
+		size = 1
+	}
+	if size > table.Amount && table.Amount > 0 {
+		size = table.Amount
+	}
+	if params := size * len(table.Columns); params > maxParameters {
+		return 0, fmt.Errorf("parse.Batch: batch_size %d in table %q requires %d parameters, maximum is %d", table.BatchSize, table.Name, params, maxParameters)
+	}
+
+	return size, nil
+}
+
+// BatchInsertQuery is like InsertQuery, but inserts multiple rows with a single statement.
+// The column args are repeated for each row, so that each row generates values
+// in the same order as rows inserted by InsertQuery.
+func (table *Table) BatchInsertQuery(rows int) (stmt string, args []interface{}, err error) {
+	defer func() {
+		if err, _ = recover().(error); err != nil {
+			err = fmt.Errorf("parse.BatchInsertQuery: %w in table %q", err, table.Name)
+		}
+	}()
+
+	if rows < 1 {
+		panic(fmt.Errorf("invalid amount of rows %d", rows))
+	}
+
+	stmt, args = table.insert(insertTmpl, rows)
+	return
+}
+
+// LoadMethod returns the Method of this table,
+// or an error if the Method is not supported.
+func (table *Table) LoadMethod() (Method, error) {
+	switch table.Method {
+	case "":
+		return InsertMethod, nil
+	case InsertMethod, CopyMethod:
+		return table.Method, nil
+	default:
+		return "", fmt.Errorf("parse.LoadMethod: unsupported method %q in table %q", table.Method, table.Name)
+	}
+}
+
+// CopyFrom returns the column names with args for this table,
+// to be used with COPY FROM.
+// Like with InsertQuery, the args generate a new value on each access
+// and produce the same values in the same order.
+func (table *Table) CopyFrom() (columns []string, args []interface{}, err error) {
+	defer func() {
+		if err, _ = recover().(error); err != nil {
+			err = fmt.Errorf("parse.CopyFrom: %w in table %q", err, table.Name)
+		}
+	}()
+
+	columns, args = table.columns()
+	return
+}
+
+// Validate builds the value generator of each column, except ReferenceType columns,
+// which need the referenced values. An error is returned for each invalid column,
+// so that all problems can be reported before anything is loaded.
+func (table *Table) Validate() (errs []error) {
+	for _, col := range table.Columns {
+		if col.Type == ReferenceType {
+			continue
+		}
+		if err := col.validate(table.Amount); err != nil {
+			errs = append(errs, fmt.Errorf("parse.Validate: %w in table %q", err, table.Name))
+		}
+	}
+	return errs
+}
+
+// chunkSeed derives the seed of a column for a chunk.
+// The first chunk uses the seed of the column.
+func chunkSeed(seed int64, chunk int) int64 {
+	const golden = 0x9E3779B97F4A7C15
+
+	return int64(uint64(seed) + uint64(chunk)*golden)
+}
+
+// Chunks splits the table in tables of ChunkSize rows,
+// which can be loaded independently of each other.
+// The columns of each chunk use a seed derived from the column seed
+// and sequences continue after the rows of the preceding chunks.
+// Therefore the generated data only depends on ChunkSize,
+// not on the order in which chunks are loaded.
+// Note that the data differs from the data generated without chunks.
+//
+// If ChunkSize is 0, the table is returned as the only chunk.
+// An error is returned for a negative ChunkSize and for tables with Unique columns,
+// as uniqueness can not be guaranteed across chunks.
+func (table *Table) Chunks() ([]*Table, error) {
+	if table.ChunkSize == 0 {
+		return []*Table{table}, nil
+	}
+	if table.ChunkSize < 0 {
+		return nil, fmt.Errorf("parse.Chunks: negative chunk_size %d in table %q", table.ChunkSize, table.Name)
+	}
+	for _, col := range table.Columns {
+		if col.Unique {
+			return nil, fmt.Errorf("parse.Chunks: unique column %q not supported with chunk_size in table %q", col.Name, table.Name)
+		}
+	}
+
+	var chunks []*Table
+
+	for offset := 0; offset < table.Amount; offset += table.ChunkSize {
+		chunk := *table
+		chunk.Amount = table.Amount - offset
+		if chunk.Amount > table.ChunkSize {
+			chunk.Amount = table.ChunkSize
+		}
+		chunk.ChunkSize = 0
+
+		chunk.Columns = make([]*Column, len(table.Columns))
+		for i, col := range table.Columns {
+			c := *col
+			c.Seed = chunkSeed(col.Seed, len(chunks))
+			c.offset = offset
+			chunk.Columns[i] = &c
+		}
+
+		chunks = append(chunks, &chunk)
+	}
+
+	return chunks, nil
+}
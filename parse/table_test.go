@@ -22,6 +22,8 @@ package parse
 
 import (
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 	"text/template"
 
@@ -61,6 +63,7 @@ func Test_Table_insert(t *testing.T) {
 		name    string
 		table   Table
 		tmpl    *template.Template
+		rows    int
 		want    string
 		want1   []interface{}
 		wantErr bool
@@ -81,6 +84,7 @@ func Test_Table_insert(t *testing.T) {
 				},
 			},
 			insertTmpl,
+			1,
 			"",
 			nil,
 			true,
@@ -103,6 +107,7 @@ func Test_Table_insert(t *testing.T) {
 				},
 			},
 			errTmpl,
+			1,
 			"",
 			nil,
 			true,
@@ -134,6 +139,7 @@ func Test_Table_insert(t *testing.T) {
 				},
 			},
 			insertTmpl,
+			1,
 			"insert into articles (published, special) values ($1, $2);",
 			[]interface{}{
 				generator.NewBool(1, 0, 1),
@@ -141,13 +147,52 @@ func Test_Table_insert(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"Multiple rows",
+			Table{
+				Name:   "articles",
+				Amount: 10,
+				Columns: []*Column{
+					{
+						Name:            "published",
+						Seed:            1,
+						NullProbability: 0,
+						Type:            BoolType,
+						Generator: map[ArgName]interface{}{
+							ProbabilityArg: 1,
+						},
+					},
+					{
+						Name:            "special",
+						Seed:            2,
+						NullProbability: 50,
+						Type:            BoolType,
+						Generator: map[ArgName]interface{}{
+							ProbabilityArg: 99,
+						},
+					},
+				},
+			},
+			insertTmpl,
+			3,
+			"insert into articles (published, special) values ($1, $2), ($3, $4), ($5, $6);",
+			[]interface{}{
+				generator.NewBool(1, 0, 1),
+				generator.NewBool(2, 50, 99),
+				generator.NewBool(1, 0, 1),
+				generator.NewBool(2, 50, 99),
+				generator.NewBool(1, 0, 1),
+				generator.NewBool(2, 50, 99),
+			},
+			false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			err := func() (err error) {
 				defer func() { err, _ = recover().(error) }()
 
-				got, got1 := tt.table.insert(tt.tmpl)
+				got, got1 := tt.table.insert(tt.tmpl, tt.rows)
 				if got != tt.want {
 					t.Errorf("Table.insert() got = %v, want %v", got, tt.want)
 				}
@@ -242,3 +287,439 @@ func Test_Table_InsertQuery(t *testing.T) {
 		})
 	}
 }
+
+func Test_Table_LoadMethod(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  Method
+		want    Method
+		wantErr bool
+	}{
+		{"Default", "", InsertMethod, false},
+		{"Insert", InsertMethod, InsertMethod, false},
+		{"Copy", CopyMethod, CopyMethod, false},
+		{"Unsupported", "foo", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := &Table{Name: "articles", Method: tt.method}
+
+			got, err := table.LoadMethod()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Table.LoadMethod() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Table.LoadMethod() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Table_CopyFrom(t *testing.T) {
+	tests := []struct {
+		name        string
+		table       Table
+		wantColumns []string
+		wantArgs    []interface{}
+		wantErr     bool
+	}{
+		{
+			"Generator error",
+			Table{
+				Name:   "articles",
+				Amount: 10,
+				Columns: []*Column{
+					{
+						Name: "published",
+						Type: BoolType,
+					},
+				},
+			},
+			nil,
+			nil,
+			true,
+		},
+		{
+			"Success",
+			Table{
+				Name:   "articles",
+				Amount: 10,
+				Method: CopyMethod,
+				Columns: []*Column{
+					{
+						Name: "published",
+						Seed: 1,
+						Type: BoolType,
+						Generator: map[ArgName]interface{}{
+							ProbabilityArg: 1,
+						},
+					},
+					{
+						Name:            "special",
+						Seed:            2,
+						NullProbability: 50,
+						Type:            BoolType,
+						Generator: map[ArgName]interface{}{
+							ProbabilityArg: 99,
+						},
+					},
+				},
+			},
+			[]string{"published", "special"},
+			[]interface{}{
+				generator.NewBool(1, 0, 1),
+				generator.NewBool(2, 50, 99),
+			},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotColumns, gotArgs, err := tt.table.CopyFrom()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Table.CopyFrom() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotColumns, tt.wantColumns) {
+				t.Errorf("Table.CopyFrom() columns = %v, want %v", gotColumns, tt.wantColumns)
+			}
+			if !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+				t.Errorf("Table.CopyFrom() args = %v, want %v", gotArgs, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func Test_Table_Validate(t *testing.T) {
+	table := Table{
+		Name:   "articles",
+		Amount: 10,
+		Columns: []*Column{
+			{
+				Name: "published",
+				Type: BoolType,
+			},
+			{
+				Name: "author",
+				Type: ReferenceType,
+				Generator: map[ArgName]interface{}{
+					TableArg:  "authors",
+					ColumnArg: "id",
+				},
+			},
+			{
+				Name:   "rating",
+				Type:   Int2Type,
+				Unique: true,
+				Generator: map[ArgName]interface{}{
+					MinArg: 1,
+					MaxArg: 5,
+				},
+			},
+			{
+				Name: "title",
+				Type: TextType,
+				Generator: map[ArgName]interface{}{
+					MinLengthArg: 1,
+					MaxLengthArg: 10,
+				},
+			},
+		},
+	}
+
+	errs := table.Validate()
+	if len(errs) != 2 {
+		t.Fatalf("Table.Validate() = %v, want 2 errors", errs)
+	}
+	for i, column := range []string{"published", "rating"} {
+		if !strings.Contains(errs[i].Error(), strconv.Quote(column)) {
+			t.Errorf("Table.Validate() error = %v, want column %q", errs[i], column)
+		}
+	}
+}
+
+func Test_Table_Batch(t *testing.T) {
+	tests := []struct {
+		name      string
+		batchSize int
+		amount    int
+		columns   int
+		want      int
+		wantErr   bool
+	}{
+		{"Default", 0, 10, 2, 1, false},
+		{"Negative", -1, 10, 2, 0, true},
+		{"Batch", 4, 10, 2, 4, false},
+		{"Larger than amount", 100, 10, 2, 10, false},
+		{"Maximum parameters", 65535, 100000, 1, 65535, false},
+		{"Too many parameters", 32768, 100000, 2, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := &Table{
+				Name:      "articles",
+				Amount:    tt.amount,
+				BatchSize: tt.batchSize,
+				Columns:   make([]*Column, tt.columns),
+			}
+
+			got, err := table.Batch()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Table.Batch() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Table.Batch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Table_BatchInsertQuery(t *testing.T) {
+	table := Table{
+		Name:   "articles",
+		Amount: 10,
+		Columns: []*Column{
+			{
+				Name: "published",
+				Seed: 1,
+				Type: BoolType,
+				Generator: map[ArgName]interface{}{
+					ProbabilityArg: 1,
+				},
+			},
+		},
+	}
+
+	conflict := table
+	conflict.OnConflict = &OnConflict{Action: ConflictNothing}
+
+	invalid := table
+	invalid.OnConflict = &OnConflict{Action: ConflictUpdate}
+
+	tests := []struct {
+		name    string
+		table   Table
+		rows    int
+		want    string
+		want1   []interface{}
+		wantErr bool
+	}{
+		{
+			"Zero rows",
+			table,
+			0,
+			"",
+			nil,
+			true,
+		},
+		{
+			"On conflict error",
+			invalid,
+			1,
+			"",
+			nil,
+			true,
+		},
+		{
+			"On conflict",
+			conflict,
+			2,
+			"insert into articles (published) values ($1), ($2) on conflict do nothing;",
+			[]interface{}{
+				generator.NewBool(1, 0, 1),
+				generator.NewBool(1, 0, 1),
+			},
+			false,
+		},
+		{
+			"Success",
+			table,
+			2,
+			"insert into articles (published) values ($1), ($2);",
+			[]interface{}{
+				generator.NewBool(1, 0, 1),
+				generator.NewBool(1, 0, 1),
+			},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, got1, err := tt.table.BatchInsertQuery(tt.rows)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Table.BatchInsertQuery() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Table.BatchInsertQuery() got = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(got1, tt.want1) {
+				t.Errorf("Table.BatchInsertQuery() got1 = %v, want %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func Test_chunkSeed(t *testing.T) {
+	if got := chunkSeed(42, 0); got != 42 {
+		t.Errorf("chunkSeed() = %d, want %d", got, 42)
+	}
+
+	seen := make(map[int64]bool)
+	for i := 0; i < 100; i++ {
+		seed := chunkSeed(1, i)
+		if seen[seed] {
+			t.Fatalf("chunkSeed() duplicate seed %d for chunk %d", seed, i)
+		}
+		seen[seed] = true
+	}
+}
+
+func Test_Table_Chunks(t *testing.T) {
+	col := func(unique bool) *Column {
+		return &Column{
+			Name: "id",
+			Seed: 1,
+			Type: Int4Type,
+			Generator: map[ArgName]interface{}{
+				ModeArg: SequenceMode,
+			},
+			Unique: unique,
+		}
+	}
+
+	tests := []struct {
+		name        string
+		table       Table
+		wantAmounts []int
+		wantOffsets []int
+		wantErr     bool
+	}{
+		{
+			"Disabled",
+			Table{Name: "articles", Amount: 10, Columns: []*Column{col(true)}},
+			[]int{10},
+			[]int{0},
+			false,
+		},
+		{
+			"Negative",
+			Table{Name: "articles", Amount: 10, ChunkSize: -1, Columns: []*Column{col(false)}},
+			nil,
+			nil,
+			true,
+		},
+		{
+			"Unique",
+			Table{Name: "articles", Amount: 10, ChunkSize: 4, Columns: []*Column{col(true)}},
+			nil,
+			nil,
+			true,
+		},
+		{
+			"Chunks",
+			Table{Name: "articles", Amount: 10, ChunkSize: 4, Columns: []*Column{col(false)}},
+			[]int{4, 4, 2},
+			[]int{0, 4, 8},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.table.Chunks()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Table.Chunks() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			var amounts, offsets []int
+			for i, chunk := range got {
+				amounts = append(amounts, chunk.Amount)
+				offsets = append(offsets, chunk.Columns[0].offset)
+
+				if seed := chunkSeed(1, i); chunk.Columns[0].Seed != seed {
+					t.Errorf("Table.Chunks() seed = %d, want %d", chunk.Columns[0].Seed, seed)
+				}
+			}
+			if !reflect.DeepEqual(amounts, tt.wantAmounts) {
+				t.Errorf("Table.Chunks() amounts = %v, want %v", amounts, tt.wantAmounts)
+			}
+			if !reflect.DeepEqual(offsets, tt.wantOffsets) {
+				t.Errorf("Table.Chunks() offsets = %v, want %v", offsets, tt.wantOffsets)
+			}
+			if c := tt.table.Columns[0]; c.offset != 0 || c.Seed != 1 {
+				t.Error("Table.Chunks() modified the columns of the table")
+			}
+		})
+	}
+}
+
+func Test_Table_LiteralInsertQuery(t *testing.T) {
+	table := &Table{
+		Name: "articles",
+		Columns: []*Column{
+			{Name: "id"},
+			{Name: "title"},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		onConflict *OnConflict
+		rows       [][]string
+		want       string
+		wantErr    bool
+	}{
+		{
+			"No rows",
+			nil,
+			nil,
+			"",
+			true,
+		},
+		{
+			"Missing value",
+			nil,
+			[][]string{{"1"}},
+			"",
+			true,
+		},
+		{
+			"On conflict error",
+			&OnConflict{Action: "foo"},
+			[][]string{{"'1'", "null"}},
+			"",
+			true,
+		},
+		{
+			"Rows",
+			nil,
+			[][]string{{"'1'", "null"}, {"'2'", "'it''s'"}},
+			"insert into articles (id, title) values ('1', null), ('2', 'it''s');",
+			false,
+		},
+		{
+			"On conflict",
+			&OnConflict{Action: ConflictNothing},
+			[][]string{{"'1'", "null"}},
+			"insert into articles (id, title) values ('1', null) on conflict do nothing;",
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table.OnConflict = tt.onConflict
+
+			got, err := table.LiteralInsertQuery(tt.rows)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Table.LiteralInsertQuery() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Table.LiteralInsertQuery() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
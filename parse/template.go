@@ -24,7 +24,6 @@ import (
 	"bytes"
 	"fmt"
 	"os"
-	"path/filepath"
 	"text/template"
 )
 
@@ -38,13 +37,10 @@ func lookupEnv(key, defValue string) string {
 
 var funcMap = template.FuncMap{"env": lookupEnv}
 
-func yamlTemplate(data interface{}, filename string) (*bytes.Buffer, error) {
-	tmpl := template.New(filepath.Base(filename))
-	tmpl.Funcs(funcMap)
-
-	var err error
-
-	tmpl, err = tmpl.ParseFiles(filename)
+// yamlTemplate executes the template text with data.
+// Name identifies the template in errors.
+func yamlTemplate(data interface{}, name string, text []byte) (*bytes.Buffer, error) {
+	tmpl, err := template.New(name).Funcs(funcMap).Parse(string(text))
 	if err != nil {
 		return nil, fmt.Errorf("parse.yamlTemplate: %w", err)
 	}
@@ -93,9 +93,18 @@ tables:
 func Test_yamlTemplate(t *testing.T) {
 	type invalidTmplData int
 
+	tmplTest, err := os.ReadFile("../testdata/tmpl_test.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	invalidTmpl, err := os.ReadFile("../testdata/invalid.tmpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	type args struct {
-		data     interface{}
-		filename string
+		data interface{}
+		text string
 	}
 	tests := []struct {
 		name    string
@@ -105,10 +114,10 @@ func Test_yamlTemplate(t *testing.T) {
 		wantErr bool
 	}{
 		{
-			"File not found",
+			"Syntax error",
 			args{
 				nil,
-				"/foo/bar/does/not/exist",
+				"{{ .NoDataFooBar",
 			},
 			nil,
 			"",
@@ -118,7 +127,7 @@ func Test_yamlTemplate(t *testing.T) {
 			"Invalid template",
 			args{
 				invalidTmplData(1),
-				"../testdata/invalid.tmpl",
+				string(invalidTmpl),
 			},
 			nil,
 			"",
@@ -128,7 +137,7 @@ func Test_yamlTemplate(t *testing.T) {
 			"Default values",
 			args{
 				nil,
-				"../testdata/tmpl_test.yml",
+				string(tmplTest),
 			},
 			nil,
 			yamlTemplateOutDefault,
@@ -138,7 +147,7 @@ func Test_yamlTemplate(t *testing.T) {
 			"Env values",
 			args{
 				nil,
-				"../testdata/tmpl_test.yml",
+				string(tmplTest),
 			},
 			map[string]string{
 				"TEST_PGDBNAME": "foo",
@@ -156,7 +165,7 @@ func Test_yamlTemplate(t *testing.T) {
 				}
 			}
 
-			buf, err := yamlTemplate(tt.args.data, tt.args.filename)
+			buf, err := yamlTemplate(tt.args.data, "tmpl_test.yml", []byte(tt.args.text))
 			if (err != nil) != tt.wantErr {
 				t.Errorf("yamlTemplate() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -0,0 +1,278 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/muhlemmer/pg_testdata/generator"
+)
+
+// now is the reference for relative timestamp arguments.
+var now = time.Now
+
+// Now is accepted by timestamp arguments as the current time.
+const Now = "now"
+
+// timestampFormats accepted by timestamp arguments.
+// These are the same formats YAML recognizes as timestamp.
+var timestampFormats = []string{
+	"2006-1-2T15:4:5.999999999Z07:00",
+	"2006-1-2t15:4:5.999999999Z07:00",
+	"2006-1-2 15:4:5.999999999",
+	"2006-1-2",
+}
+
+// timeOfDayFormats accepted by time of day arguments.
+var timeOfDayFormats = []string{
+	"15:04:05.999999",
+	"15:04",
+}
+
+// granularities accepted by GranularityArg.
+var granularities = map[string]time.Duration{
+	"microsecond": time.Microsecond,
+	"millisecond": time.Millisecond,
+	"second":      time.Second,
+	"minute":      time.Minute,
+	"hour":        time.Hour,
+	"day":         generator.Day,
+}
+
+var durationRegexp = regexp.MustCompile(`^([+-]?)(?:(\d+)d)?(.*)$`)
+
+// parseDuration extends time.ParseDuration with a "d" unit of 24 hours,
+// which may only be used as the leading unit. For example "-30d" or "1d12h".
+func parseDuration(s string) (time.Duration, error) {
+	m := durationRegexp.FindStringSubmatch(s)
+	sign, days, rest := m[1], m[2], m[3]
+
+	var d time.Duration
+
+	if rest != "" || days == "" {
+		var err error
+		if d, err = time.ParseDuration(rest); err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		if d < 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+	}
+
+	if days != "" {
+		var n int64
+		if _, err := fmt.Sscan(days, &n); err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		d += time.Duration(n) * generator.Day
+	}
+
+	if sign == "-" {
+		d = -d
+	}
+
+	return d, nil
+}
+
+// assertDuration returns the duration Generator argument arg,
+// such as "1h30m" or "-30d".
+func (c *Column) assertDuration(arg ArgName) time.Duration {
+	switch v := c.Generator[arg].(type) {
+	case time.Duration:
+		return v
+	case string:
+		d, err := parseDuration(v)
+		if err != nil {
+			c.panic(fmt.Errorf("%q %w", arg, err))
+		}
+		return d
+	default:
+		c.panic(fmt.Errorf("%q incorrect type: %T, expected: duration", arg, v))
+		return 0
+	}
+}
+
+// assertTime returns the timestamp Generator argument arg.
+// Timestamps without time zone are interpreted as UTC.
+// Besides absolute timestamps, "now" or a duration relative to now
+// with an explicit sign, such as "-30d" or "+1h", are accepted.
+func (c *Column) assertTime(arg ArgName) time.Time {
+	switch v := c.Generator[arg].(type) {
+	case time.Time:
+		return v
+	case string:
+		if v == Now {
+			return now()
+		}
+
+		if len(v) > 0 && (v[0] == '-' || v[0] == '+') {
+			d, err := parseDuration(v)
+			if err != nil {
+				c.panic(fmt.Errorf("%q %w", arg, err))
+			}
+			return now().Add(d)
+		}
+
+		for _, format := range timestampFormats {
+			if t, err := time.Parse(format, v); err == nil {
+				return t
+			}
+		}
+
+		c.panic(fmt.Errorf("%q invalid timestamp %q", arg, v))
+		return time.Time{}
+	default:
+		c.panic(fmt.Errorf("%q incorrect type: %T, expected: timestamp", arg, v))
+		return time.Time{}
+	}
+}
+
+// assertTimeOfDay returns the time of day Generator argument arg,
+// such as "08:30" or "17:15:30.5", as duration since midnight.
+func (c *Column) assertTimeOfDay(arg ArgName) time.Duration {
+	v, ok := c.Generator[arg].(string)
+	if !ok {
+		c.panic(fmt.Errorf("%q incorrect type: %T, expected: time of day", arg, c.Generator[arg]))
+	}
+
+	if v == "24:00" || v == "24:00:00" {
+		return generator.Day
+	}
+
+	for _, format := range timeOfDayFormats {
+		if t, err := time.Parse(format, v); err == nil {
+			return t.Sub(time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC))
+		}
+	}
+
+	c.panic(fmt.Errorf("%q invalid time of day %q", arg, v))
+	return 0
+}
+
+// granularity returns the GranularityArg, which defaults to "second".
+func (c *Column) granularity(tp TypeName) time.Duration {
+	if _, ok := c.Generator[GranularityArg]; !ok {
+		return time.Second
+	}
+
+	name := c.assertString(GranularityArg)
+	g, ok := granularities[name]
+	if !ok {
+		c.panic(fmt.Errorf("unsupported %q %q for type %q", GranularityArg, name, tp))
+	}
+
+	return g
+}
+
+// checkGranularity panics if no multiple of granularity exists between
+// min and max, which are offsets to a granularity aligned reference.
+func (c *Column) checkGranularity(min, max, granularity time.Duration) {
+	first := min.Truncate(granularity)
+	if first < min {
+		first += granularity
+	}
+
+	if first > max {
+		c.panic(fmt.Errorf("no %v granularity value between %q and %q", granularity, MinArg, MaxArg))
+	}
+}
+
+// timeRange returns the required min and max timestamp arguments.
+// It panics if min is after max,
+// or when there is no multiple of granularity in between.
+func (c *Column) timeRange(tp TypeName, granularity time.Duration) (min, max time.Time) {
+	c.requiredGenOpts(tp, MinArg, MaxArg)
+
+	min, max = c.assertTime(MinArg), c.assertTime(MaxArg)
+	if min.After(max) {
+		c.panic(fmt.Errorf("%q %v after %q %v", MinArg, min, MaxArg, max))
+	}
+
+	// Truncate is aligned to midnight UTC, as granularity is always a divisor of a day.
+	first := min.Truncate(granularity)
+	if first.Before(min) {
+		first = first.Add(granularity)
+	}
+	if first.After(max) {
+		c.panic(fmt.Errorf("no %v granularity value between %q and %q", granularity, MinArg, MaxArg))
+	}
+
+	return min, max
+}
+
+func (c *Column) timestampType() generator.Value {
+	g := c.granularity(TimestampType)
+	min, max := c.timeRange(TimestampType, g)
+
+	return generator.NewTimestamp(c.Seed, c.NullProbability, min, max, g)
+}
+
+func (c *Column) timestamptzType() generator.Value {
+	g := c.granularity(TimestamptzType)
+	min, max := c.timeRange(TimestamptzType, g)
+
+	return generator.NewTimestamptz(c.Seed, c.NullProbability, min, max, g)
+}
+
+func (c *Column) dateType() generator.Value {
+	if _, ok := c.Generator[GranularityArg]; ok && c.granularity(DateType) != generator.Day {
+		c.panic(fmt.Errorf("unsupported %q %q for type %q", GranularityArg, c.Generator[GranularityArg], DateType))
+	}
+
+	min, max := c.timeRange(DateType, generator.Day)
+
+	return generator.NewDate(c.Seed, c.NullProbability, min, max)
+}
+
+// timeType uses the whole day when min and / or max are absent.
+func (c *Column) timeType() generator.Value {
+	g := c.granularity(TimeType)
+	min, max := time.Duration(0), generator.Day-time.Microsecond
+
+	if _, ok := c.Generator[MinArg]; ok {
+		min = c.assertTimeOfDay(MinArg)
+	}
+	if _, ok := c.Generator[MaxArg]; ok {
+		max = c.assertTimeOfDay(MaxArg)
+	}
+	if min > max {
+		c.panic(fmt.Errorf("%q %v greater than %q %v", MinArg, min, MaxArg, max))
+	}
+	c.checkGranularity(min, max, g)
+
+	return generator.NewTime(c.Seed, c.NullProbability, min, max, g)
+}
+
+func (c *Column) intervalType() generator.Value {
+	c.requiredGenOpts(IntervalType, MinArg, MaxArg)
+
+	g := c.granularity(IntervalType)
+	min, max := c.assertDuration(MinArg), c.assertDuration(MaxArg)
+
+	if min > max {
+		c.panic(fmt.Errorf("%q %v greater than %q %v", MinArg, min, MaxArg, max))
+	}
+	c.checkGranularity(min, max, g)
+
+	return generator.NewInterval(c.Seed, c.NullProbability, min, max, g)
+}
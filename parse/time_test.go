@@ -0,0 +1,339 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/muhlemmer/pg_testdata/generator"
+)
+
+func Test_parseDuration(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 0, true},
+		{"d", 0, true},
+		{"foo", 0, true},
+		{"1d-1h", 0, true},
+		{"1h30m", 90 * time.Minute, false},
+		{"-1h30m", -90 * time.Minute, false},
+		{"30d", 30 * generator.Day, false},
+		{"-30d", -30 * generator.Day, false},
+		{"+1d12h", 36 * time.Hour, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := parseDuration(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseDuration() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("parseDuration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+var testNow = time.Date(2021, 10, 1, 12, 30, 0, 0, time.UTC)
+
+func init() {
+	now = func() time.Time { return testNow }
+}
+
+func Test_column_assertTime(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       interface{}
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			"time",
+			testNow,
+			testNow,
+			false,
+		},
+		{
+			"now",
+			"now",
+			testNow,
+			false,
+		},
+		{
+			"relative",
+			"-30d",
+			testNow.Add(-30 * generator.Day),
+			false,
+		},
+		{
+			"invalid relative",
+			"-30x",
+			time.Time{},
+			true,
+		},
+		{
+			"date",
+			"2021-09-01",
+			time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC),
+			false,
+		},
+		{
+			"timestamp",
+			"2021-09-01 14:15:16.5",
+			time.Date(2021, 9, 1, 14, 15, 16, 5e8, time.UTC),
+			false,
+		},
+		{
+			"RFC3339",
+			"2021-09-01T14:15:16Z",
+			time.Date(2021, 9, 1, 14, 15, 16, 0, time.UTC),
+			false,
+		},
+		{
+			"invalid string",
+			"foo",
+			time.Time{},
+			true,
+		},
+		{
+			"int",
+			1,
+			time.Time{},
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Column{
+				Name:      "test",
+				Generator: map[ArgName]interface{}{MinArg: tt.v},
+			}
+
+			err := func() (err error) {
+				defer func() { err, _ = recover().(error) }()
+
+				if got := c.assertTime(MinArg); !got.Equal(tt.want) {
+					t.Errorf("column.assertTime() = %v, want %v", got, tt.want)
+				}
+
+				return
+			}()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("column.assertTime() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
+
+func Test_column_assertTimeOfDay(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       interface{}
+		want    time.Duration
+		wantErr bool
+	}{
+		{"minutes", "08:30", 8*time.Hour + 30*time.Minute, false},
+		{"fraction", "17:15:30.5", 17*time.Hour + 15*time.Minute + 30500*time.Millisecond, false},
+		{"midnight", "24:00:00", 24 * time.Hour, false},
+		{"invalid", "25:00", 0, true},
+		{"int", 8, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Column{
+				Name:      "test",
+				Generator: map[ArgName]interface{}{MinArg: tt.v},
+			}
+
+			err := func() (err error) {
+				defer func() { err, _ = recover().(error) }()
+
+				if got := c.assertTimeOfDay(MinArg); got != tt.want {
+					t.Errorf("column.assertTimeOfDay() = %v, want %v", got, tt.want)
+				}
+
+				return
+			}()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("column.assertTimeOfDay() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
+
+func Test_column_timeTypes(t *testing.T) {
+	min := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	max := time.Date(2021, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		Type      TypeName
+		Generator map[ArgName]interface{}
+		want      generator.Value
+		wantErr   bool
+	}{
+		{
+			"timestamp missing arg",
+			TimestampType,
+			map[ArgName]interface{}{MinArg: "2021-01-01"},
+			nil,
+			true,
+		},
+		{
+			"timestamp min after max",
+			TimestampType,
+			map[ArgName]interface{}{MinArg: "2021-12-31", MaxArg: "2021-01-01"},
+			nil,
+			true,
+		},
+		{
+			"timestamp unsupported granularity",
+			TimestampType,
+			map[ArgName]interface{}{MinArg: "2021-01-01", MaxArg: "2021-12-31", GranularityArg: "week"},
+			nil,
+			true,
+		},
+		{
+			"timestamp no granular value",
+			TimestampType,
+			map[ArgName]interface{}{MinArg: "2021-01-01 10:00:00", MaxArg: "2021-01-01 20:00:00", GranularityArg: "day"},
+			nil,
+			true,
+		},
+		{
+			"timestamp",
+			TimestampType,
+			map[ArgName]interface{}{MinArg: "2021-01-01", MaxArg: "2021-12-31"},
+			generator.NewTimestamp(1, 2, min, max, time.Second),
+			false,
+		},
+		{
+			"timestamptz relative",
+			TimestamptzType,
+			map[ArgName]interface{}{MinArg: "-30d", MaxArg: "now", GranularityArg: "minute"},
+			generator.NewTimestamptz(1, 2, testNow.Add(-30*generator.Day), testNow, time.Minute),
+			false,
+		},
+		{
+			"date granularity",
+			DateType,
+			map[ArgName]interface{}{MinArg: "2021-01-01", MaxArg: "2021-12-31", GranularityArg: "hour"},
+			nil,
+			true,
+		},
+		{
+			"date",
+			DateType,
+			map[ArgName]interface{}{MinArg: "2021-01-01", MaxArg: "2021-12-31", GranularityArg: "day"},
+			generator.NewDate(1, 2, min, max),
+			false,
+		},
+		{
+			"time default range",
+			TimeType,
+			map[ArgName]interface{}{},
+			generator.NewTime(1, 2, 0, generator.Day-time.Microsecond, time.Second),
+			false,
+		},
+		{
+			"time min greater than max",
+			TimeType,
+			map[ArgName]interface{}{MinArg: "17:00", MaxArg: "08:00"},
+			nil,
+			true,
+		},
+		{
+			"time no granular value",
+			TimeType,
+			map[ArgName]interface{}{MinArg: "08:01", MaxArg: "08:59", GranularityArg: "hour"},
+			nil,
+			true,
+		},
+		{
+			"time",
+			TimeType,
+			map[ArgName]interface{}{MinArg: "08:00", MaxArg: "17:00", GranularityArg: "minute"},
+			generator.NewTime(1, 2, 8*time.Hour, 17*time.Hour, time.Minute),
+			false,
+		},
+		{
+			"interval missing arg",
+			IntervalType,
+			map[ArgName]interface{}{MinArg: "1h"},
+			nil,
+			true,
+		},
+		{
+			"interval invalid",
+			IntervalType,
+			map[ArgName]interface{}{MinArg: "1h", MaxArg: 3},
+			nil,
+			true,
+		},
+		{
+			"interval min greater than max",
+			IntervalType,
+			map[ArgName]interface{}{MinArg: "2h", MaxArg: "1h"},
+			nil,
+			true,
+		},
+		{
+			"interval",
+			IntervalType,
+			map[ArgName]interface{}{MinArg: "-1h", MaxArg: "30d"},
+			generator.NewInterval(1, 2, -time.Hour, 30*generator.Day, time.Second),
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Column{
+				Seed:            1,
+				NullProbability: 2,
+				Type:            tt.Type,
+				Generator:       tt.Generator,
+			}
+
+			err := func() (err error) {
+				defer func() { err, _ = recover().(error) }()
+				if got := c.valueGenerator(1000); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Column.valueGenerator() = %v, want %v", got, tt.want)
+				}
+				return nil
+			}()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("column.valueGenerator() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import "fmt"
+
+// Transaction mode for loading rows.
+type Transaction string
+
+const (
+	NoTransaction    Transaction = "none"  // Each statement is committed on its own, the default.
+	BatchTransaction Transaction = "batch" // Rows are committed every CommitEvery rows.
+	AllTransaction   Transaction = "all"   // All rows of a Table, or of the whole Config, are committed at once.
+)
+
+func checkTransaction(tx Transaction) error {
+	switch tx {
+	case "", NoTransaction, BatchTransaction, AllTransaction:
+		return nil
+	default:
+		return fmt.Errorf("unsupported transaction %q", tx)
+	}
+}
+
+// RunTransaction reports if all tables are loaded in a single transaction,
+// which is the case if the Config uses AllTransaction.
+func (conf *Config) RunTransaction() (bool, error) {
+	if err := checkTransaction(conf.Transaction); err != nil {
+		return false, fmt.Errorf("parse.RunTransaction: %w", err)
+	}
+
+	return conf.Transaction == AllTransaction, nil
+}
+
+// CommitRows returns the amount of rows of table to load in each transaction,
+// or 0 if each statement is committed on its own.
+// The Transaction and CommitEvery of the table default to those of the Config.
+// If the Config uses AllTransaction, the table is loaded in the transaction
+// of the run and 0 is returned.
+//
+// An error is returned for unsupported transaction modes,
+// if BatchTransaction is used without a positive CommitEvery,
+// if the table sets a Transaction while the Config uses AllTransaction,
+// or if the table uses AllTransaction with a ChunkSize.
+// CommitRows must be called with the table, not with its Chunks.
+func (conf *Config) CommitRows(table *Table) (rows int, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("parse.CommitRows: %w in table %q", err, table.Name)
+		}
+	}()
+
+	if err = checkTransaction(conf.Transaction); err != nil {
+		return 0, err
+	}
+	if err = checkTransaction(table.Transaction); err != nil {
+		return 0, err
+	}
+
+	if conf.Transaction == AllTransaction {
+		if table.Transaction != "" {
+			return 0, fmt.Errorf("transaction %q not supported with transaction %q of the config", table.Transaction, conf.Transaction)
+		}
+		return 0, nil
+	}
+
+	tx, every := table.Transaction, table.CommitEvery
+	if tx == "" {
+		tx = conf.Transaction
+	}
+	if every == 0 {
+		every = conf.CommitEvery
+	}
+
+	switch tx {
+	case BatchTransaction:
+		if every < 1 {
+			return 0, fmt.Errorf("commit_every %d must be positive for transaction %q", every, tx)
+		}
+		return every, nil
+	case AllTransaction:
+		// Chunks are loaded concurrently, each on its own connection.
+		if table.ChunkSize > 0 {
+			return 0, fmt.Errorf("chunk_size %d not supported with transaction %q", table.ChunkSize, tx)
+		}
+		return table.Amount, nil
+	default:
+		return 0, nil
+	}
+}
@@ -0,0 +1,166 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package parse
+
+import "testing"
+
+func TestConfig_RunTransaction(t *testing.T) {
+	tests := []struct {
+		name    string
+		tx      Transaction
+		want    bool
+		wantErr bool
+	}{
+		{"Default", "", false, false},
+		{"None", NoTransaction, false, false},
+		{"Batch", BatchTransaction, false, false},
+		{"All", AllTransaction, true, false},
+		{"Unsupported", "foo", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := &Config{Transaction: tt.tx}
+
+			got, err := conf.RunTransaction()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Config.RunTransaction() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Config.RunTransaction() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_CommitRows(t *testing.T) {
+	tests := []struct {
+		name    string
+		conf    Config
+		table   Table
+		want    int
+		wantErr bool
+	}{
+		{
+			"Default",
+			Config{},
+			Table{Amount: 100},
+			0,
+			false,
+		},
+		{
+			"Unsupported config",
+			Config{Transaction: "foo"},
+			Table{Amount: 100},
+			0,
+			true,
+		},
+		{
+			"Unsupported table",
+			Config{},
+			Table{Amount: 100, Transaction: "foo"},
+			0,
+			true,
+		},
+		{
+			"Run transaction",
+			Config{Transaction: AllTransaction},
+			Table{Amount: 100},
+			0,
+			false,
+		},
+		{
+			"Table transaction in run transaction",
+			Config{Transaction: AllTransaction},
+			Table{Amount: 100, Transaction: BatchTransaction, CommitEvery: 10},
+			0,
+			true,
+		},
+		{
+			"Config batch",
+			Config{Transaction: BatchTransaction, CommitEvery: 10},
+			Table{Amount: 100},
+			10,
+			false,
+		},
+		{
+			"Table commit every",
+			Config{Transaction: BatchTransaction, CommitEvery: 10},
+			Table{Amount: 100, CommitEvery: 20},
+			20,
+			false,
+		},
+		{
+			"Table batch",
+			Config{CommitEvery: 10},
+			Table{Amount: 100, Transaction: BatchTransaction},
+			10,
+			false,
+		},
+		{
+			"Batch without commit every",
+			Config{},
+			Table{Amount: 100, Transaction: BatchTransaction},
+			0,
+			true,
+		},
+		{
+			"Table none",
+			Config{Transaction: BatchTransaction, CommitEvery: 10},
+			Table{Amount: 100, Transaction: NoTransaction},
+			0,
+			false,
+		},
+		{
+			"Table all",
+			Config{},
+			Table{Amount: 100, Transaction: AllTransaction},
+			100,
+			false,
+		},
+		{
+			"Table all with chunks",
+			Config{},
+			Table{Amount: 100, Transaction: AllTransaction, ChunkSize: 10},
+			0,
+			true,
+		},
+		{
+			"Config all with chunks",
+			Config{Transaction: AllTransaction},
+			Table{Amount: 100, ChunkSize: 10},
+			0,
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.conf.CommitRows(&tt.table)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Config.CommitRows() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Config.CommitRows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package pgtestdata seeds a database with test data from Go tests.
+package pgtestdata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/muhlemmer/pg_testdata/loader"
+	"github.com/muhlemmer/pg_testdata/parse"
+)
+
+// describe returns the message of err, including the table and column
+// reported by PostgreSQL, if any.
+func describe(err error) string {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || (pgErr.TableName == "" && pgErr.ColumnName == "") {
+		return err.Error()
+	}
+
+	return fmt.Sprintf("%v (table %q, column %q)", err, pgErr.TableName, pgErr.ColumnName)
+}
+
+// Seed loads the tables of conf in a transaction of conn,
+// which is rolled back when the test and its subtests complete.
+// The seeded rows are only visible through the returned transaction.
+// The test fails immediately if the config can not be loaded.
+// Transactions of the config use savepoints of the transaction.
+func Seed(t testing.TB, conn loader.DB, conf *parse.Config) pgx.Tx {
+	t.Helper()
+
+	ctx := context.Background()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		t.Fatalf("pgtestdata.Seed: %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		tx.Rollback(ctx)
+	})
+
+	if _, err = loader.New(conf, loader.NewDBSink(tx)).Load(ctx); err != nil {
+		t.Fatalf("pgtestdata.Seed: %s", describe(err))
+	}
+
+	return tx
+}
+
+// SeedFile seeds the tables of the config in file, as Seed.
+func SeedFile(t testing.TB, conn loader.DB, file string) pgx.Tx {
+	t.Helper()
+
+	conf, err := parse.Load(file)
+	if err != nil {
+		t.Fatalf("pgtestdata.SeedFile: %v", err)
+	}
+
+	return Seed(t, conn, conf)
+}
@@ -0,0 +1,169 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package pgtestdata
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgconn"
+	"github.com/muhlemmer/pg_testdata/parse"
+)
+
+func Test_describe(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			"Error",
+			errors.New("foo"),
+			"foo",
+		},
+		{
+			"PgError",
+			fmt.Errorf("insert: %w", &pgconn.PgError{Severity: "ERROR", Message: "null value", Code: "23502", TableName: "articles", ColumnName: "title"}),
+			`insert: ERROR: null value (SQLSTATE 23502) (table "articles", column "title")`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describe(tt.err); got != tt.want {
+				t.Errorf("describe() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// recorder is a testing.TB which records a fatal message
+// and the cleanup functions.
+type recorder struct {
+	testing.TB
+	msg      string
+	cleanups []func()
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Cleanup(f func()) {
+	r.cleanups = append(r.cleanups, f)
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.msg = fmt.Sprintf(format, args...)
+	runtime.Goexit()
+}
+
+// run f in a goroutine, as testing does, followed by the cleanups.
+func (r *recorder) run(f func(t testing.TB)) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f(r)
+	}()
+	<-done
+
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+}
+
+func countRows(t *testing.T) (n int) {
+	if err := testDB.QueryRow(testCtx, "select count(*) from unit_tests;").Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestSeed(t *testing.T) {
+	table := func(name string) *parse.Table {
+		return &parse.Table{
+			Name:   name,
+			Amount: 5,
+			Columns: []*parse.Column{
+				{
+					Name: "bool_col",
+					Type: parse.BoolType,
+					Generator: map[parse.ArgName]interface{}{
+						parse.ProbabilityArg: 100,
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		conf    *parse.Config
+		wantMsg string
+	}{
+		{
+			"Error",
+			&parse.Config{Tables: []*parse.Table{table("unit_tests"), table("error_tests")}},
+			`table "error_tests", column "text_col"`,
+		},
+		{
+			"Success",
+			&parse.Config{Tables: []*parse.Table{table("unit_tests")}},
+			"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := countRows(t)
+
+			r := new(recorder)
+			r.run(func(rt testing.TB) {
+				tx := Seed(rt, testDB, tt.conf)
+
+				var n int
+				if err := tx.QueryRow(testCtx, "select count(*) from unit_tests;").Scan(&n); err != nil {
+					t.Error(err)
+				}
+				if n != before+5 {
+					t.Errorf("Seed() rows = %d, want %d", n, before+5)
+				}
+			})
+
+			if !strings.Contains(r.msg, tt.wantMsg) || (tt.wantMsg == "") != (r.msg == "") {
+				t.Errorf("Seed() message = %q, want %q", r.msg, tt.wantMsg)
+			}
+			if n := countRows(t); n != before {
+				t.Errorf("Seed() rows after cleanup = %d, want %d", n, before)
+			}
+		})
+	}
+}
+
+func TestSeedFile(t *testing.T) {
+	r := new(recorder)
+	r.run(func(rt testing.TB) {
+		SeedFile(rt, testDB, "does_not_exist.yml")
+	})
+
+	if !strings.HasPrefix(r.msg, "pgtestdata.SeedFile: ") {
+		t.Errorf("SeedFile() message = %q", r.msg)
+	}
+}
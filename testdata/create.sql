@@ -10,4 +10,61 @@ create table error_tests (
 create table regression_tests (
     bool_col_n  bool    null,
     bool_col_nn bool    not null
+);
+
+create table regression_copy_tests (
+    bool_col_n  bool    null,
+    bool_col_nn bool    not null
+);
+
+create table regression_batch_tests (
+    bool_col_n  bool    null,
+    bool_col_nn bool    not null
+);
+
+create table regression_dry_run_tests (
+    bool_col_n  bool    null,
+    bool_col_nn bool    not null
+);
+
+create table regression_export_tests (
+    bool_col_n  bool    null,
+    bool_col_nn bool    not null
+);
+
+create table regression_parallel_tests (
+    id          int4    primary key,
+    bool_col_n  bool    null,
+    bool_col_nn bool    not null
+);
+
+create table reference_parents (
+    id          int4    primary key
+);
+
+create table "Reference_Keys" (
+    "order"     int4    primary key
+);
+
+create table reference_children (
+    parent_id   int8    not null references reference_parents (id)
+);
+
+create type init_status as enum ('active', 'suspended', 'deleted');
+
+create table init_tests (
+    id          serial      primary key,
+    code        uuid        not null unique,
+    qty         int4        not null check (qty > 0 and qty <= 100),
+    price       numeric(8,2)    check (price >= 0),
+    name        varchar(20) not null,
+    status      init_status not null,
+    parent_id   int4        references reference_parents (id),
+    doc         json
+);
+
+create table init_tree_tests (
+    id          int4    primary key,
+    parent_id   int4    references init_tree_tests (id),
+    label       text    not null
 );
\ No newline at end of file
@@ -1,3 +1,14 @@
+drop table if exists init_tree_tests;
+drop table if exists init_tests;
+drop type if exists init_status;
+drop table if exists reference_children;
+drop table if exists reference_parents;
+drop table if exists "Reference_Keys";
 drop table if exists unit_tests;
 drop table if exists error_tests;
 drop table if exists regression_tests;
+drop table if exists regression_copy_tests;
+drop table if exists regression_batch_tests;
+drop table if exists regression_parallel_tests;
+drop table if exists regression_dry_run_tests;
+drop table if exists regression_export_tests;
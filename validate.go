@@ -0,0 +1,224 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/muhlemmer/pg_testdata/parse"
+)
+
+const missingTablesQuery = `select name
+from unnest($1::text[]) as name
+where to_regclass(name) is null;`
+
+const tableColumnsQuery = `select t.name,
+	a.attname::text,
+	case when ty.typtype = 'd' then bt.typname else ty.typname end::text,
+	case when ty.typtype = 'd' then bt.typtype else ty.typtype end::text = 'e',
+	a.attnotnull,
+	a.atthasdef or a.attidentity <> '' or a.attgenerated <> ''
+from unnest($1::text[]) as t(name)
+join pg_attribute a on a.attrelid = to_regclass(t.name)
+join pg_type ty on ty.oid = a.atttypid
+left join pg_type bt on bt.oid = ty.typbasetype
+where a.attnum > 0 and not a.attisdropped
+order by t.name, a.attnum;`
+
+// schemaColumn as read from the catalog.
+type schemaColumn struct {
+	typeName   string
+	enum       bool
+	notNull    bool
+	hasDefault bool
+}
+
+// textTypes accept values of text generators,
+// which are sent in text format.
+var textTypes = []string{"text", "varchar", "bpchar", "name", "citext"}
+
+// compatibleTypes holds the column types which accept the values
+// of each generator type. Binary encoded values require an exact type match.
+var compatibleTypes = map[parse.TypeName][]string{
+	parse.BoolType:        {"bool"},
+	parse.Int2Type:        {"int2"},
+	parse.Int4Type:        {"int4"},
+	parse.Int8Type:        {"int8"},
+	parse.NumericType:     {"numeric"},
+	parse.Float4Type:      {"float4"},
+	parse.Float8Type:      {"float8"},
+	parse.TextType:        textTypes,
+	parse.VarcharType:     textTypes,
+	parse.CharType:        textTypes,
+	parse.EnumType:        textTypes,
+	parse.DateType:        {"date"},
+	parse.TimeType:        {"time"},
+	parse.TimestampType:   {"timestamp"},
+	parse.TimestamptzType: {"timestamptz"},
+	parse.IntervalType:    {"interval"},
+	parse.UUIDType:        {"uuid"},
+}
+
+// compatible reports if a column of type sc accepts the values of a generator of type tp.
+// Reference values are sent in text format, or converted from text to the column type for COPY.
+// They are accepted by any type, which can parse the text of the referenced values.
+// Enum columns accept text values.
+func compatible(tp parse.TypeName, sc *schemaColumn) bool {
+	if tp == parse.ReferenceType {
+		return true
+	}
+
+	types, ok := compatibleTypes[tp]
+	if !ok {
+		return false
+	}
+	if sc.enum {
+		switch tp {
+		case parse.TextType, parse.VarcharType, parse.CharType, parse.EnumType:
+			return true
+		default:
+			return false
+		}
+	}
+
+	for _, t := range types {
+		if t == sc.typeName {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaErrors holds all problems found when validating a config against the schema.
+type schemaErrors []string
+
+func (e schemaErrors) Error() string {
+	return fmt.Sprintf("config does not match schema:\n\t%s", strings.Join(e, "\n\t"))
+}
+
+// validateSchema compares all tables and columns in conf to the schema in the database
+// and builds the generators of the columns, see parse.Table.Validate.
+// All problems found are collected and passed to panic() in a schemaErrors,
+// before anything is inserted.
+func validateSchema(ctx context.Context, pool *pgxpool.Pool, conf *parse.Config) {
+	names := make([]string, len(conf.Tables))
+	for i, table := range conf.Tables {
+		names[i] = table.Name
+	}
+
+	missing := make(map[string]bool)
+	queryRows(ctx, pool, missingTablesQuery, func(rows pgx.Rows) error {
+		var name string
+		err := rows.Scan(&name)
+		missing[name] = true
+		return err
+	}, names)
+
+	schema := make(map[string]map[string]*schemaColumn)
+	var order [][2]string
+
+	queryRows(ctx, pool, tableColumnsQuery, func(rows pgx.Rows) error {
+		var table, column string
+		sc := new(schemaColumn)
+
+		if err := rows.Scan(&table, &column, &sc.typeName, &sc.enum, &sc.notNull, &sc.hasDefault); err != nil {
+			return err
+		}
+
+		if schema[table] == nil {
+			schema[table] = make(map[string]*schemaColumn)
+		}
+		schema[table][column] = sc
+		order = append(order, [2]string{table, column})
+
+		return nil
+	}, names)
+
+	var errs schemaErrors
+
+	if _, err := conf.RunTransaction(); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	for _, table := range conf.Tables {
+		if _, err := table.LoadMethod(); err != nil {
+			errs = append(errs, err.Error())
+		}
+		if _, err := table.Batch(); err != nil {
+			errs = append(errs, err.Error())
+		}
+		if _, err := table.Chunks(); err != nil {
+			errs = append(errs, err.Error())
+		}
+		if _, err := conf.CommitRows(table); err != nil {
+			errs = append(errs, err.Error())
+		}
+		if _, err := table.BeforeLoad(false); err != nil {
+			errs = append(errs, err.Error())
+		}
+		if _, err := table.Conflict(); err != nil {
+			errs = append(errs, err.Error())
+		}
+		for _, err := range table.Validate() {
+			errs = append(errs, err.Error())
+		}
+		if missing[table.Name] {
+			errs = append(errs, fmt.Sprintf("table %q does not exist", table.Name))
+			continue
+		}
+
+		for _, col := range table.Columns {
+			sc, ok := schema[table.Name][col.Name]
+			if !ok {
+				errs = append(errs, fmt.Sprintf("column %q does not exist in table %q", col.Name, table.Name))
+				continue
+			}
+
+			if !compatible(col.Type, sc) {
+				errs = append(errs, fmt.Sprintf("type %q of column %q in table %q is incompatible with column type %q", col.Type, col.Name, table.Name, sc.typeName))
+			}
+			if sc.notNull && col.NullProbability > 0 {
+				errs = append(errs, fmt.Sprintf("column %q in table %q is not null, but has a null probability of %g", col.Name, table.Name, col.NullProbability))
+			}
+		}
+	}
+
+	configured := make(map[[2]string]bool)
+	for _, table := range conf.Tables {
+		for _, col := range table.Columns {
+			configured[[2]string{table.Name, col.Name}] = true
+		}
+	}
+
+	for _, key := range order {
+		if sc := schema[key[0]][key[1]]; sc.notNull && !sc.hasDefault && !configured[key] {
+			errs = append(errs, fmt.Sprintf("column %q in table %q is not null without default, but missing in the config", key[1], key[0]))
+		}
+	}
+
+	if len(errs) > 0 {
+		panic(errs)
+	}
+}
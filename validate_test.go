@@ -0,0 +1,120 @@
+/*
+SPDX-License-Identifier: AGPL-3.0-only
+
+pg_testdata is a test data generator for PostgreSQL.
+Copyright (C) 2021  Tim Mohlmann
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"github.com/muhlemmer/pg_testdata/parse"
+)
+
+func Test_compatible(t *testing.T) {
+	tests := []struct {
+		name string
+		tp   parse.TypeName
+		sc   *schemaColumn
+		want bool
+	}{
+		{"exact", parse.Int4Type, &schemaColumn{typeName: "int4"}, true},
+		{"binary mismatch", parse.Int2Type, &schemaColumn{typeName: "int4"}, false},
+		{"text to varchar", parse.TextType, &schemaColumn{typeName: "varchar"}, true},
+		{"text to enum", parse.TextType, &schemaColumn{typeName: "status", enum: true}, true},
+		{"enum to enum", parse.EnumType, &schemaColumn{typeName: "status", enum: true}, true},
+		{"int to enum", parse.Int4Type, &schemaColumn{typeName: "status", enum: true}, false},
+		{"reference", parse.ReferenceType, &schemaColumn{typeName: "int8"}, true},
+		{"unsupported", "unsupported", &schemaColumn{typeName: "int8"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compatible(tt.tp, tt.sc); got != tt.want {
+				t.Errorf("compatible() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_validateSchema(t *testing.T) {
+	boolCol := func(name string, nullProbability float32) *parse.Column {
+		return &parse.Column{
+			Name:            name,
+			NullProbability: nullProbability,
+			Type:            parse.BoolType,
+			Generator:       map[parse.ArgName]interface{}{parse.ProbabilityArg: 50},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		tables   []*parse.Table
+		wantErrs int
+	}{
+		{
+			"Valid",
+			[]*parse.Table{
+				{Name: "unit_tests", Columns: []*parse.Column{boolCol("bool_col", 10)}},
+				{Name: "regression_tests", Columns: []*parse.Column{boolCol("bool_col_n", 10), boolCol("bool_col_nn", 0)}},
+			},
+			0,
+		},
+		{
+			"All problems",
+			[]*parse.Table{
+				{Name: "does_not_exist", Columns: []*parse.Column{boolCol("bool_col", 0)}},
+				{Name: "unit_tests", Columns: []*parse.Column{boolCol("foo", 0)}},
+				{Name: "regression_tests", Columns: []*parse.Column{boolCol("bool_col_n", 0), boolCol("bool_col_nn", 10)}},
+				{Name: "error_tests", Columns: []*parse.Column{boolCol("bool_col", 0)}},
+				{Name: "reference_parents", Columns: []*parse.Column{boolCol("id", 0)}},
+			},
+			// Missing table, missing column, null probability,
+			// missing not null column and type mismatch.
+			5,
+		},
+		{
+			"Generator problems",
+			[]*parse.Table{
+				{Name: "unit_tests", Amount: 10, Columns: []*parse.Column{{Name: "bool_col", Type: parse.BoolType}}},
+				{Name: "regression_tests", Amount: 10, Columns: []*parse.Column{
+					boolCol("bool_col_n", 0),
+					{Name: "bool_col_nn", Type: parse.BoolType, Unique: true, Generator: map[parse.ArgName]interface{}{parse.ProbabilityArg: 50}},
+				}},
+			},
+			// Missing probability and too many unique values.
+			2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := func() (err error) {
+				defer func() { err, _ = recover().(error) }()
+				validateSchema(testCtx, testDB, &parse.Config{Tables: tt.tables})
+				return nil
+			}()
+
+			errs, _ := err.(schemaErrors)
+			if err != nil && errs == nil {
+				t.Fatalf("validateSchema() unexpected error = %v", err)
+			}
+			if len(errs) != tt.wantErrs {
+				t.Errorf("validateSchema() errors = %v, want %d", err, tt.wantErrs)
+			}
+		})
+	}
+}